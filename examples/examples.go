@@ -5,6 +5,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"regexp"
+	"time"
 
 	"github.com/go-faster/errors"
 	"github.com/gotd/td/telegram/query"
@@ -12,11 +14,20 @@ import (
 )
 
 func telegramConnection() {
-	client := telegram.ClientTelegram()
+	client, err := telegram.ClientTelegram(nil, nil)
+	if err != nil {
+		panic(err)
+	}
 
 	if err := client.Run(context.Background(), func(ctx context.Context) error {
 		// authenticate user
-		telegram.AuthTelegram(client, ctx)
+		authConfig, err := telegram.AuthConfigFromEnv()
+		if err != nil {
+			return err
+		}
+		if err := telegram.AuthTelegram(client, ctx, authConfig); err != nil {
+			return err
+		}
 
 		// It is only valid to use client while this function is not returned
 		// and ctx is not cancelled.
@@ -35,14 +46,23 @@ func telegramConnection() {
 		fmt.Println("Current user:", name)
 
 		raw := tg.NewClient(client)
-		listChannels, err := telegram.ListChannelsFromFolders(ctx, raw, 4)
+		sources, err := telegram.ListSourcesFromFolders(ctx, raw, "4")
 		if err != nil {
-			return errors.Wrap(err, "list channels from folder")
+			return errors.Wrap(err, "list sources from folder")
 		}
 
-		for _, channel := range listChannels {
-			if err := telegram.SearchProductInChannel(ctx, raw, channel, "SSD"); err != nil {
-				return errors.Wrap(err, "search product in channel")
+		product := regexp.MustCompile("(?i)SSD")
+		for _, source := range sources {
+			// Últimas duas horas, sem cursor.
+			messages, err := telegram.FetchMessages(ctx, raw, source.Peer, time.Now().Add(-2*time.Hour), 0)
+			if err != nil {
+				return errors.Wrapf(err, "fetch messages of %s", source.Title)
+			}
+
+			for _, message := range messages {
+				if product.MatchString(message.Message) {
+					fmt.Printf("🔍 [%s] %s\n\n", source.Title, message.Message)
+				}
 			}
 		}
 
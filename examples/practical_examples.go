@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 	"strings"
 
+	"bot-telegram/src/pkg/telegram"
+
 	"github.com/gotd/td/telegram/query"
 	"github.com/gotd/td/tg"
 )
@@ -178,36 +181,29 @@ func findChatByName(ctx context.Context, raw *tg.Client, searchName string) erro
 }
 
 // Exemplo 4: Organizar automaticamente em folders
-func autoOrganizeFolders(ctx context.Context, raw *tg.Client) error {
+func autoOrganizeFolders(ctx context.Context, raw *tg.Client, apply bool) error {
 	fmt.Println("🤖 === ORGANIZAÇÃO AUTOMÁTICA DE FOLDERS ===")
 
-	// Este é apenas um exemplo conceitual
-	// Na prática, você precisaria implementar a lógica específica
-
-	fmt.Println("📝 Passos para organização automática:")
-	fmt.Println("1. Analisar todos os chats")
-	fmt.Println("2. Categorizar por tipo (work, personal, news, etc)")
-	fmt.Println("3. Criar folders automaticamente")
-	fmt.Println("4. Adicionar chats aos folders apropriados")
-
-	// Exemplos de categorização:
-	categories := map[string][]string{
-		"Trabalho":   {"work", "empresa", "projeto", "equipe"},
-		"Notícias":   {"news", "notícias", "jornal", "mídia"},
-		"Família":    {"família", "family", "parente"},
-		"Amigos":     {"amigos", "friends", "pessoal"},
-		"Tecnologia": {"tech", "dev", "programming", "código"},
+	// 1. Analisar todos os chats
+	sources, err := telegram.ListDialogSources(ctx, raw)
+	if err != nil {
+		return err
 	}
 
-	fmt.Println("\n🏷️  Categorias sugeridas:")
-	for category, keywords := range categories {
-		fmt.Printf("   📁 %s: %v\n", category, keywords)
+	// 2. Categorizar pelas mensagens recentes de cada chat
+	plan, err := telegram.PlanFolders(ctx, raw, sources, telegram.OrganizeOptions{})
+	if err != nil {
+		return err
 	}
+	telegram.PrintPlan(os.Stdout, plan)
 
-	fmt.Println("\n⚠️  Nota: Este é um exemplo conceitual.")
-	fmt.Println("⚠️  Implemente a lógica específica conforme suas necessidades.")
+	if !apply {
+		fmt.Println("\n💡 Dry-run: nada foi alterado.")
+		return nil
+	}
 
-	return nil
+	// 3. Criar folders e adicionar os chats
+	return telegram.ApplyFolders(ctx, raw, plan)
 }
 
 // Exemplo 5: Backup das configurações de folders
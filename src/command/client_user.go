@@ -2,88 +2,377 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"io"
+	"log"
+	"net/http"
 	"os"
+	"strconv"
+	"sync/atomic"
 	"time"
 
 	"bot-telegram/src/internal/domain"
+	"bot-telegram/src/pkg/api"
+	"bot-telegram/src/pkg/catalog"
+	"bot-telegram/src/pkg/followup"
+	"bot-telegram/src/pkg/links"
+	"bot-telegram/src/pkg/notify"
+	"bot-telegram/src/pkg/pipeline"
+	"bot-telegram/src/pkg/sqlstore"
 	supabase "bot-telegram/src/pkg/supabase"
 	"bot-telegram/src/pkg/telegram"
 
 	"github.com/go-faster/errors"
+	tgclient "github.com/gotd/td/telegram"
+	"github.com/gotd/td/telegram/auth/qrlogin"
 	"github.com/gotd/td/tg"
 	"github.com/joho/godotenv"
+	supabaseClient "github.com/supabase-community/supabase-go"
 )
 
+// defaultFolder é a pasta do Telegram com os canais e grupos de promoção,
+// usada pelas sessões sem pasta configurada.
+const defaultFolder = "4"
+
 func main() {
 	// Using ".env" file to load environment variables.
 	if err := godotenv.Load(); err != nil && !os.IsNotExist(err) {
 		panic(err)
 	}
 
-	sessionProductsToSearch := make(chan []domain.Product)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-	client := telegram.ClientTelegram()
-	if err := client.Run(context.Background(), func(ctx context.Context) error {
-		// authenticate user
-		telegram.AuthTelegram(client, ctx)
+	command, args := "run", os.Args[1:]
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
 
-		raw := tg.NewClient(client)
-		listChannels, err := telegram.ListChannelsFromFolders(ctx, raw, 4)
-		if err != nil {
-			return errors.Wrap(err, "list channels from folder")
+	var err error
+	switch command {
+	case "run":
+		err = run(ctx)
+	case "folders":
+		err = foldersCommand(ctx, args)
+	case "migrate":
+		err = migrateCommand(ctx, args)
+	default:
+		fmt.Fprintf(os.Stderr, "usage: %s [run|folders|migrate] ...\n", os.Args[0])
+		err = errUsage
+	}
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+	case errors.Is(err, errUsage):
+		// O uso já foi impresso.
+		os.Exit(2)
+	default:
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// errUsage é retornado pelos comandos chamados com argumentos inválidos,
+// depois de imprimir o uso; main sai com código 2.
+var errUsage = errors.New("invalid arguments")
+
+// parseFlags lê as flags de um comando. O flag já imprime o erro e o uso, então
+// um argumento inválido vira errUsage; -h retorna flag.ErrHelp.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	err := flags.Parse(args)
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	return errUsage
+}
+
+// run busca os produtos de todas as sessões nos canais do Telegram.
+func run(ctx context.Context) error {
+	repo, db, err := openRepository(ctx)
+	if err != nil {
+		return err
+	}
+	if closer, ok := repo.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	authConfig, err := telegram.AuthConfigFromEnv()
+	if err != nil {
+		return err
+	}
+
+	server, err := api.NewServerFromEnv()
+	if err != nil {
+		return err
+	}
+	if server != nil {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		if authConfig.Code == nil {
+			codeHandler := telegram.NewCodeHandler()
+			server.Handle("POST /auth/code", codeHandler)
+			authConfig.Code = codeHandler
+		}
+		if authConfig.Mode == telegram.LoginQR {
+			qrHandler := telegram.NewQRHandler()
+			server.Handle("GET /auth/qr.png", qrHandler)
+			authConfig.ShowQR = func(ctx context.Context, token qrlogin.Token) error {
+				if err := telegram.PrintQR(os.Stdout, token); err != nil {
+					return err
+				}
+				return qrHandler.Show(ctx, token)
+			}
 		}
 
-		go searchProductsInChannels(sessionProductsToSearch, listChannels, ctx, raw)
+		go func() {
+			if err := server.Run(ctx); err != nil {
+				log.Print(err)
+			}
+		}()
+	}
+
+	accounts, err := telegram.AccountsFromEnv()
+	if err != nil {
+		return err
+	}
+
+	pool, err := telegram.NewPool(accounts, telegram.PoolOptions{
+		Auth: authConfig,
+		Storage: func(account telegram.Account) (tgclient.SessionStorage, error) {
+			return sessionStorage(repo, db, account)
+		},
+	})
+	if err != nil {
+		return err
+	}
 
-		if err := ListAllProducts(sessionProductsToSearch); err != nil {
-			panic(err)
+	if server != nil {
+		server.Handle("POST /providers", api.JoinHandler(func(ctx context.Context, link string) (domain.Provider, error) {
+			return catalog.Join(ctx, pool, repo, link, configuredFolder())
+		}))
+	}
+
+	return pool.Run(ctx, func(ctx context.Context) error {
+		if os.Getenv("SYNC_PROVIDERS") == "true" {
+			count, err := catalog.Sync(ctx, pool, repo)
+			if err != nil {
+				return err
+			}
+			log.Printf("%d providers synced", count)
+		}
+
+		var matches atomic.Int64
+		matcher := pipeline.NewRegexMatcher()
+		p, err := newPipeline(repo, pool, matcher, &matches)
+		if err != nil {
+			return errors.Wrap(err, "create pipeline")
 		}
 
-		time.Sleep(10 * time.Second)
-		close(sessionProductsToSearch)
+		checker := newChecker(repo, pool)
+
+		if os.Getenv("SCHEDULE") == "true" {
+			return runScheduled(ctx, repo, db, p, checker, matcher, &matches)
+		}
 
 		// Return to close client connection and free up resources.
-		return nil
-	}); err != nil {
-		panic(err)
+		return runPipeline(ctx, repo, p, checker, &matches)
+	})
+}
+
+// runPipeline executa o pipeline, registra a execução em runs e confere os
+// posts dos matches anteriores, mesmo se a execução falhou.
+func runPipeline(ctx context.Context, repo domain.RunRepository, p *pipeline.Pipeline, checker *followup.Checker, matches *atomic.Int64) error {
+	matches.Store(0)
+	started := time.Now()
+	runErr := p.Run(ctx)
+
+	record := domain.Run{
+		StartedAt:  started.UTC().Format(time.RFC3339),
+		FinishedAt: time.Now().UTC().Format(time.RFC3339),
+		Matches:    int(matches.Load()),
+	}
+	if runErr != nil {
+		record.Error = runErr.Error()
+	}
+	if err := repo.SaveRun(context.WithoutCancel(ctx), record); err != nil {
+		log.Print(err)
+	}
+	if ctx.Err() != nil {
+		return runErr
+	}
+
+	// As edições e exclusões só são vistas aqui, então a conferência roda
+	// mesmo quando a execução falha.
+	updated, err := checker.Check(ctx)
+	if err != nil {
+		log.Printf("[FOLLOWUP] %v", err)
+	}
+	if updated > 0 {
+		log.Printf("%d matches updated", updated)
+	}
+
+	return runErr
+}
+
+// newChecker acompanha as edições e exclusões dos posts; NOTIFY_UPDATES=false
+// só atualiza os matches, sem avisar.
+func newChecker(repo domain.Repository, pool *telegram.Pool) *followup.Checker {
+	checker := &followup.Checker{
+		Matches: repo,
+		Messages: followup.MessageLookupFunc(func(ctx context.Context, sourceID int64, account string, ids []int) (map[int]tg.MessageClass, error) {
+			var messages map[int]tg.MessageClass
+			// Um grupo comum só é relido pela conta que leu o match; sem ela o
+			// post não é conferido, em vez de parecer apagado.
+			err := pool.DoAs(ctx, sourceID, account, func(ctx context.Context, raw *tg.Client, source telegram.Source) error {
+				var err error
+				messages, err = telegram.GetMessages(ctx, raw, source.Peer, ids)
+				return err
+			})
+			return messages, err
+		}),
+	}
+	if os.Getenv("NOTIFY_UPDATES") != "false" {
+		checker.Notifier = newNotifier(repo)
+	}
+	return checker
+}
+
+// newNotifier envia cada match para os canais do dono.
+func newNotifier(repo domain.NotificationRepository) *notify.Router {
+	return &notify.Router{
+		Channels: repo,
+		Senders: map[string]notify.Sender{
+			domain.ChannelWebhook:  notify.Webhook{Client: &http.Client{Timeout: 10 * time.Second}},
+			domain.ChannelTelegram: notify.TelegramBot{Token: os.Getenv("TELEGRAM_BOT_TOKEN"), Client: &http.Client{Timeout: 10 * time.Second}},
+		},
+		Fallback: notify.Writer(os.Stdout),
+	}
+}
+
+// sessionStorage escolhe onde guardar a sessão do Telegram (TELEGRAM_SESSION_STORAGE).
+// nil usa o arquivo local padrão; db usa a tabela telegram_sessions do
+// DB_BACKEND (supabase, postgres ou sqlite).
+func sessionStorage(repo domain.Repository, db *supabaseClient.Client, account telegram.Account) (tgclient.SessionStorage, error) {
+	backend := os.Getenv("TELEGRAM_SESSION_STORAGE")
+	switch backend {
+	case "", "file":
+		return nil, nil
+	case "db", "supabase":
+	default:
+		return nil, errors.Errorf("unknown TELEGRAM_SESSION_STORAGE %q", backend)
 	}
 
-	// inicia a connecção com o telegram e registra goroutines para buscar os produtos no telegram
-	// 	criar um canal para executar a busca
-	// Iniciar o cronjob e registrar as sessions
-	// 	a cada execução, publicar os produtos a serem pesquisados nos canais
+	key, err := telegram.SessionKeyFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, errors.New("TELEGRAM_SESSION_KEY is required to store the session in the database")
+	}
 
-	// Client is closed.
+	if db != nil {
+		return &supabase.SessionStorage{Client: db, ID: account.Phone}, nil
+	}
+	if store, ok := repo.(*sqlstore.Repository); ok && backend == "db" {
+		return store.SessionStorage(account.Phone), nil
+	}
+	return nil, errors.Errorf("TELEGRAM_SESSION_STORAGE=%s is not supported with DB_BACKEND=%q", backend, os.Getenv("DB_BACKEND"))
 }
 
-func searchProductsInChannels(list chan []domain.Product, listChannels []*tg.InputPeerChannel, ctx context.Context, raw *tg.Client) {
+// newPipeline monta o pipeline; matches conta os matches gravados.
+func newPipeline(repo domain.Repository, pool *telegram.Pool, matcher pipeline.Matcher, matches *atomic.Int64) (*pipeline.Pipeline, error) {
+	return pipeline.New(pipeline.Config{
+		Matcher: matcher,
+		Sessions: pipeline.SessionLoaderFunc(func(ctx context.Context) ([]pipeline.Job, error) {
+			return loadSessions(ctx, repo, repo)
+		}),
+		Sources: pipeline.SourceResolverFunc(func(ctx context.Context, session domain.Session) ([]telegram.Source, error) {
+			return resolveSources(ctx, pool, session)
+		}),
+		Messages: pipeline.MessageFetcherFunc(func(ctx context.Context, source telegram.Source, minID int) ([]*tg.Message, error) {
+			var messages []*tg.Message
+			// O cursor de um grupo comum é da conta que o listou.
+			err := pool.DoAs(ctx, source.ID, source.Account, func(ctx context.Context, raw *tg.Client, source telegram.Source) error {
+				var err error
+				messages, err = telegram.FetchMessages(ctx, raw, source.Peer, time.Now().Add(-2*time.Hour), minID)
+				return err
+			})
+			return messages, err
+		}),
+		// Um fetch por conta em paralelo.
+		FetchWorkers: pool.Size(),
+		Links:        &links.Resolver{Client: &http.Client{Timeout: 10 * time.Second}},
+		Prices:       repo,
+		Cursors:      repo,
+		Store: pipeline.StoreFunc(func(ctx context.Context, match domain.Match) error {
+			if err := repo.SaveMatch(ctx, match); err != nil {
+				return err
+			}
+			matches.Add(1)
+			return nil
+		}),
+		Notifier:    newNotifier(repo),
+		NotifyEnded: os.Getenv("NOTIFY_ENDED") == "true",
+	})
+}
 
-	itemsProducts := <-list
+// resolveSources retorna os providers escolhidos na sessão ou, se não houver
+// nenhum, os chats da pasta da sessão.
+func resolveSources(ctx context.Context, pool *telegram.Pool, session domain.Session) ([]telegram.Source, error) {
+	if len(session.ProviderIds) > 0 {
+		sources, err := pool.ListDialogSources(ctx)
+		if err != nil {
+			return nil, err
+		}
 
-	for _, channel := range listChannels {
-		if err := telegram.SearchProductInChannel(ctx, raw, channel, itemsProducts[1].Name); err != nil {
-			fmt.Print(errors.Wrap(err, "search product in channel"))
+		wanted := make(map[string]bool, len(session.ProviderIds))
+		for _, id := range session.ProviderIds {
+			wanted[id] = true
 		}
+
+		var selected []telegram.Source
+		for _, source := range sources {
+			if wanted[strconv.FormatInt(source.ID, 10)] {
+				selected = append(selected, source)
+			}
+		}
+		return selected, nil
+	}
+
+	folder := session.Folder
+	if folder == "" {
+		folder = configuredFolder()
 	}
+	return pool.ListSourcesFromFolders(ctx, folder)
 }
 
-func ListAllProducts(channel chan []domain.Product) error {
-	client, err := supabase.NewClient()
-	if err != nil {
-		return errors.Wrap(err, "error connect supabase")
+func configuredFolder() string {
+	if folder := os.Getenv("TELEGRAM_FOLDER"); folder != "" {
+		return folder
 	}
+	return defaultFolder
+}
 
-	listProducts, err := supabase.GetAllProducts(client, &domain.Session{SessionId: "bac"})
+func loadSessions(ctx context.Context, sessionRepo domain.SessionRepository, productRepo domain.ProductRepository) ([]pipeline.Job, error) {
+	sessions, err := sessionRepo.ListSessions(ctx)
 	if err != nil {
-		return errors.Wrap(err, "error connect supabase")
+		return nil, errors.Wrap(err, "list sessions")
 	}
 
-	channel <- listProducts
-
-	for _, product := range listProducts {
-		println("Product:", product.ProductID, product.Title)
+	jobs := make([]pipeline.Job, 0, len(sessions))
+	for _, session := range sessions {
+		// Sem dono, a sessão não tem produtos nem canais de quem quer que seja.
+		if session.OwnerID == "" {
+			log.Printf("session %s has no user_id, skipping", session.SessionId)
+			continue
+		}
+		products, err := productRepo.ListProducts(ctx, session.OwnerID, session.ProductIds)
+		if err != nil {
+			return nil, errors.Wrapf(err, "list products of session %s", session.SessionId)
+		}
+		jobs = append(jobs, pipeline.Job{Session: session, Products: products})
 	}
 
-	return nil
+	return jobs, nil
 }
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"bot-telegram/src/internal/domain"
+	supabase "bot-telegram/src/pkg/supabase"
+	"bot-telegram/src/pkg/telegram"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/telegram/auth/qrlogin"
+	"github.com/gotd/td/tg"
+	supabaseClient "github.com/supabase-community/supabase-go"
+)
+
+const foldersUsage = `usage: folders <command> [-account phone] [args]
+
+commands:
+  list
+  create [-emoji E] [-channels] [-groups] <title> [peer...]
+  delete <folder>
+  add-peer <folder> <peer>
+  remove-peer <folder> <peer>
+  export [-o file]
+  import <file>
+  organize [-apply] [-sample N] [-from folder]
+
+<folder> is the folder ID or title; <peer> is @username, a t.me link or the chat ID.
+`
+
+// foldersCommand gerencia as pastas (dialog filters) de uma conta.
+func foldersCommand(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, foldersUsage)
+		return errUsage
+	}
+	command, args := args[0], args[1:]
+
+	flags := flag.NewFlagSet("folders "+command, flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, foldersUsage) }
+	account := flags.String("account", "", "phone of the account, from TELEGRAM_ACCOUNTS_FILE")
+
+	switch command {
+	case "list":
+		if err := parseFlags(flags, args); err != nil {
+			return err
+		}
+		return withTelegram(ctx, *account, listFolders)
+
+	case "create":
+		emoji := flags.String("emoji", "", "folder emoji")
+		channels := flags.Bool("channels", false, "include every channel")
+		groups := flags.Bool("groups", false, "include every group")
+		if err := parseFlags(flags, args); err != nil {
+			return err
+		}
+		if flags.NArg() < 1 {
+			flags.Usage()
+			return errUsage
+		}
+		// O Telegram recusa pasta sem nenhum chat.
+		if flags.NArg() == 1 && !*channels && !*groups {
+			return errors.New("folders create: informe ao menos um peer, -channels ou -groups")
+		}
+		return withTelegram(ctx, *account, func(ctx context.Context, raw *tg.Client) error {
+			filter := &tg.DialogFilter{
+				Title:      tg.TextWithEntities{Text: flags.Arg(0)},
+				Emoticon:   *emoji,
+				Broadcasts: *channels,
+				Groups:     *groups,
+			}
+			for _, ref := range flags.Args()[1:] {
+				peer, err := telegram.ResolvePeer(ctx, raw, ref)
+				if err != nil {
+					return err
+				}
+				filter.IncludePeers = append(filter.IncludePeers, peer)
+			}
+
+			id, err := telegram.CreateFolder(ctx, raw, filter)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("✅ Pasta '%s' criada com sucesso! (ID: %d)\n", flags.Arg(0), id)
+			return nil
+		})
+
+	case "delete":
+		if err := parseFlags(flags, args); err != nil {
+			return err
+		}
+		if flags.NArg() != 1 {
+			flags.Usage()
+			return errUsage
+		}
+		return withTelegram(ctx, *account, func(ctx context.Context, raw *tg.Client) error {
+			if err := telegram.DeleteFolder(ctx, raw, flags.Arg(0)); err != nil {
+				return err
+			}
+			fmt.Printf("✅ Pasta %s deletada com sucesso!\n", flags.Arg(0))
+			return nil
+		})
+
+	case "add-peer", "remove-peer":
+		if err := parseFlags(flags, args); err != nil {
+			return err
+		}
+		if flags.NArg() != 2 {
+			flags.Usage()
+			return errUsage
+		}
+		return withTelegram(ctx, *account, func(ctx context.Context, raw *tg.Client) error {
+			peer, err := telegram.ResolvePeer(ctx, raw, flags.Arg(1))
+			if err != nil {
+				return err
+			}
+			if command == "add-peer" {
+				err = telegram.AddToFolder(ctx, raw, flags.Arg(0), peer)
+			} else {
+				err = telegram.RemoveFromFolder(ctx, raw, flags.Arg(0), peer)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Printf("✅ Pasta %s atualizada\n", flags.Arg(0))
+			return nil
+		})
+
+	case "export":
+		output := flags.String("o", "", "output file (default stdout)")
+		if err := parseFlags(flags, args); err != nil {
+			return err
+		}
+		return withTelegram(ctx, *account, func(ctx context.Context, raw *tg.Client) error {
+			backups, err := telegram.ExportFolders(ctx, raw)
+			if err != nil {
+				return err
+			}
+
+			var w io.Writer = os.Stdout
+			if *output != "" {
+				file, err := os.Create(*output)
+				if err != nil {
+					return errors.Wrap(err, "create export file")
+				}
+				defer file.Close()
+				w = file
+			}
+
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(backups)
+		})
+
+	case "import":
+		if err := parseFlags(flags, args); err != nil {
+			return err
+		}
+		if flags.NArg() != 1 {
+			flags.Usage()
+			return errUsage
+		}
+		data, err := os.ReadFile(flags.Arg(0))
+		if err != nil {
+			return errors.Wrap(err, "read import file")
+		}
+		var backups []telegram.FolderBackup
+		if err := json.Unmarshal(data, &backups); err != nil {
+			return errors.Wrap(err, "parse import file")
+		}
+
+		return withTelegram(ctx, *account, func(ctx context.Context, raw *tg.Client) error {
+			missing, err := telegram.ImportFolders(ctx, raw, backups)
+			for _, ref := range missing {
+				fmt.Printf("⚠️  Chat não encontrado nesta conta: %s (ID: %d)\n", ref.Title, ref.ID)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Printf("✅ %d pastas importadas\n", len(backups))
+			return nil
+		})
+
+	case "organize":
+		apply := flags.Bool("apply", false, "apply the changes instead of only printing them")
+		sample := flags.Int("sample", 50, "recent messages read from each chat")
+		from := flags.String("from", "", "only organize the chats of this folder (default every chat)")
+		if err := parseFlags(flags, args); err != nil {
+			return err
+		}
+		return withTelegram(ctx, *account, func(ctx context.Context, raw *tg.Client) error {
+			var (
+				sources []telegram.Source
+				err     error
+			)
+			if *from != "" {
+				sources, err = telegram.ListSourcesFromFolders(ctx, raw, *from)
+			} else {
+				sources, err = telegram.ListDialogSources(ctx, raw)
+			}
+			if err != nil {
+				return err
+			}
+
+			plan, err := telegram.PlanFolders(ctx, raw, sources, telegram.OrganizeOptions{Sample: *sample})
+			if err != nil {
+				return err
+			}
+			telegram.PrintPlan(os.Stdout, plan)
+
+			if !*apply || len(plan) == 0 {
+				return nil
+			}
+			if err := telegram.ApplyFolders(ctx, raw, plan); err != nil {
+				return err
+			}
+			fmt.Println("✅ Pastas organizadas")
+			return nil
+		})
+
+	default:
+		fmt.Fprint(os.Stderr, foldersUsage)
+		return errUsage
+	}
+}
+
+func listFolders(ctx context.Context, raw *tg.Client) error {
+	folders, err := telegram.ListFolders(ctx, raw)
+	if err != nil {
+		return err
+	}
+
+	if len(folders) == 0 {
+		fmt.Println("📭 Nenhuma pasta configurada")
+		return nil
+	}
+
+	for _, folder := range folders {
+		switch f := folder.(type) {
+		case *tg.DialogFilter:
+			fmt.Printf("📁 %s %s (ID: %d) - incluídos: %d, excluídos: %d, fixados: %d\n",
+				f.Title.Text, f.Emoticon, f.ID, len(f.IncludePeers), len(f.ExcludePeers), len(f.PinnedPeers))
+		case *tg.DialogFilterChatlist:
+			fmt.Printf("🔗 %s %s (ID: %d) - Compartilhada - incluídos: %d, fixados: %d\n",
+				f.Title.Text, f.Emoticon, f.ID, len(f.IncludePeers), len(f.PinnedPeers))
+		}
+	}
+
+	return nil
+}
+
+// withTelegram loga na conta phone (ou na primeira configurada) e chama fn.
+func withTelegram(ctx context.Context, phone string, fn func(ctx context.Context, raw *tg.Client) error) error {
+	accounts, err := telegram.AccountsFromEnv()
+	if err != nil {
+		return err
+	}
+
+	account := accounts[0]
+	if phone != "" {
+		found := false
+		for _, a := range accounts {
+			if a.Phone == phone {
+				account, found = a, true
+				break
+			}
+		}
+		if !found {
+			return errors.Errorf("account %s not configured", phone)
+		}
+	}
+
+	var (
+		repo domain.Repository
+		db   *supabaseClient.Client
+	)
+	switch os.Getenv("TELEGRAM_SESSION_STORAGE") {
+	case "supabase":
+		if db, err = supabase.NewClient(ctx); err != nil {
+			return err
+		}
+	case "db":
+		if repo, db, err = openRepository(ctx); err != nil {
+			return err
+		}
+		if closer, ok := repo.(io.Closer); ok {
+			defer closer.Close()
+		}
+	}
+	storage, err := sessionStorage(repo, db, account)
+	if err != nil {
+		return err
+	}
+
+	authConfig, err := telegram.AuthConfigFromEnv()
+	if err != nil {
+		return err
+	}
+	authConfig.Phone = account.Phone
+	if account.Password != "" {
+		authConfig.Password = account.Password
+	}
+
+	dispatcher := tg.NewUpdateDispatcher()
+	if authConfig.Mode == telegram.LoginQR {
+		authConfig.LoggedIn = qrlogin.OnLoginToken(dispatcher)
+	}
+
+	client, err := telegram.NewClient(account, dispatcher, storage)
+	if err != nil {
+		return err
+	}
+
+	return client.Run(ctx, func(ctx context.Context) error {
+		if err := telegram.AuthTelegram(client, ctx, authConfig); err != nil {
+			return err
+		}
+		return fn(ctx, client.API())
+	})
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"bot-telegram/src/pkg/config"
+	"bot-telegram/src/pkg/sqlstore"
+
+	"github.com/go-faster/errors"
+)
+
+// migrateCommand cria ou atualiza as tabelas do banco escolhido.
+func migrateCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	backend := flags.String("backend", os.Getenv("DB_BACKEND"), "supabase, postgres or sqlite (default DB_BACKEND)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	var (
+		dialect sqlstore.Dialect
+		dsn     string
+		err     error
+	)
+	switch *backend {
+	case "", "supabase":
+		// O PostgREST não executa DDL: as migrações vão direto no banco do projeto.
+		dialect = sqlstore.Supabase
+		dsn, err = config.Secret("SUPABASE_DB_URL")
+		if err == nil && dsn == "" {
+			err = errors.New("SUPABASE_DB_URL is required to migrate supabase")
+		}
+	case "postgres":
+		dialect = sqlstore.Postgres
+		dsn, err = config.Secret("DB_DSN")
+		if err == nil && dsn == "" {
+			err = errors.New("DB_DSN is required to migrate postgres")
+		}
+	case "sqlite":
+		dialect = sqlstore.SQLite
+		if dsn = os.Getenv("DB_DSN"); dsn == "" {
+			dsn = "promotions.db"
+		}
+	default:
+		err = errors.Errorf("unknown backend %q", *backend)
+	}
+	if err != nil {
+		return err
+	}
+
+	applied, err := sqlstore.Migrate(ctx, dialect, dsn)
+	for _, name := range applied {
+		fmt.Printf("✅ %s\n", name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		fmt.Println("Banco já está atualizado")
+	}
+
+	return nil
+}
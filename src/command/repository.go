@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"bot-telegram/src/internal/domain"
+	"bot-telegram/src/pkg/config"
+	"bot-telegram/src/pkg/memory"
+	"bot-telegram/src/pkg/sqlstore"
+	supabase "bot-telegram/src/pkg/supabase"
+
+	"github.com/go-faster/errors"
+	supabaseClient "github.com/supabase-community/supabase-go"
+)
+
+// openRepository escolhe onde ficam sessões, produtos e matches (DB_BACKEND).
+// db é o client do Supabase, nil nos outros backends.
+func openRepository(ctx context.Context) (repo domain.Repository, db *supabaseClient.Client, err error) {
+	switch backend := os.Getenv("DB_BACKEND"); backend {
+	case "", "supabase":
+		cfg, err := supabase.ConfigFromEnv()
+		if err != nil {
+			return nil, nil, err
+		}
+		db, err := supabase.NewClientWithConfig(ctx, cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		return supabase.Repository{Client: db}, db, nil
+	case "memory":
+		path := os.Getenv("DB_SEED_FILE")
+		if path == "" {
+			return &memory.Repository{}, nil, nil
+		}
+		repo, err := memory.Load(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return repo, nil, nil
+	case "sqlite":
+		dsn := os.Getenv("DB_DSN")
+		if dsn == "" {
+			dsn = "promotions.db"
+		}
+		repo, err := sqlstore.Open(ctx, sqlstore.SQLite, dsn)
+		if err != nil {
+			return nil, nil, err
+		}
+		return repo, nil, nil
+	case "postgres":
+		dsn, err := config.Secret("DB_DSN")
+		if err != nil {
+			return nil, nil, err
+		}
+		if dsn == "" {
+			return nil, nil, errors.New("DB_DSN is required for DB_BACKEND=postgres")
+		}
+		repo, err := sqlstore.Open(ctx, sqlstore.Postgres, dsn)
+		if err != nil {
+			return nil, nil, err
+		}
+		return repo, nil, nil
+	default:
+		return nil, nil, errors.Errorf("unknown DB_BACKEND %q", backend)
+	}
+}
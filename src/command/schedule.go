@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"bot-telegram/src/internal/domain"
+	"bot-telegram/src/pkg/followup"
+	"bot-telegram/src/pkg/pipeline"
+	"bot-telegram/src/pkg/schedule"
+	supabase "bot-telegram/src/pkg/supabase"
+
+	"github.com/go-faster/errors"
+	supabaseClient "github.com/supabase-community/supabase-go"
+)
+
+// runScheduled roda cada sessão no seu cron_schedule até ctx terminar. No
+// backend supabase, as mudanças em sessions e products feitas no dashboard
+// recarregam o agendamento e o matcher sem reiniciar o processo.
+func runScheduled(ctx context.Context, repo domain.Repository, db *supabaseClient.Client, p *pipeline.Pipeline, checker *followup.Checker, matcher *pipeline.RegexMatcher, matches *atomic.Int64) error {
+	scheduler := &schedule.Scheduler{
+		Run: func(ctx context.Context, sessionIDs []string) error {
+			log.Printf("[SCHEDULE] running sessions %v", sessionIDs)
+			return runPipeline(pipeline.OnlySessions(ctx, sessionIDs...), repo, p, checker, matches)
+		},
+	}
+
+	reload := func() error {
+		sessions, err := repo.ListSessions(ctx)
+		if err != nil {
+			return errors.Wrap(err, "list sessions")
+		}
+		if err := scheduler.Reload(sessions); err != nil {
+			// As sessões com cron inválido ficam de fora; as outras seguem.
+			log.Print(err)
+		}
+		log.Printf("[SCHEDULE] %d sessions scheduled", len(sessions))
+		return nil
+	}
+	if err := reload(); err != nil {
+		return err
+	}
+
+	if db != nil && os.Getenv("SUPABASE_REALTIME") != "false" {
+		realtime, err := supabase.NewRealtime(db)
+		if err != nil {
+			return err
+		}
+
+		changed := make(chan string, 1)
+		go func() {
+			// Um ChangeResync, depois de reconectar, também recarrega: as
+			// mudanças feitas durante a queda se perderam.
+			err := realtime.Listen(ctx, []string{"sessions", "products"}, func(change supabase.Change) {
+				select {
+				case changed <- change.Table:
+				default:
+				}
+			})
+			if err != nil {
+				log.Print(err)
+			}
+		}()
+		go watchChanges(ctx, changed, reload, matcher)
+	}
+
+	return scheduler.Start(ctx)
+}
+
+// watchChanges recarrega as sessões e o matcher quando o dashboard muda as
+// tabelas. Mudanças seguidas são agrupadas em uma recarga.
+func watchChanges(ctx context.Context, changed <-chan string, reload func() error, matcher *pipeline.RegexMatcher) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-changed:
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(2 * time.Second):
+		}
+		// Os produtos são lidos a cada execução; só as regex em cache ficam
+		// velhas.
+		matcher.Reset()
+		if err := reload(); err != nil {
+			log.Print(err)
+		}
+	}
+}
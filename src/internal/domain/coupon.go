@@ -0,0 +1,9 @@
+package domain
+
+type Coupon struct {
+	Code string `json:"code"`
+	// Discount as posted, e.g. "10%" or "R$ 50".
+	Discount string `json:"discount,omitempty"`
+	// Expires is the validity hint as posted, e.g. "31/12" or "hoje".
+	Expires string `json:"expires,omitempty"`
+}
@@ -0,0 +1,36 @@
+package domain
+
+// Estados de um match, atualizados quando o post é editado ou apagado.
+const (
+	MatchActive       = "active"
+	MatchSoldOut      = "sold_out"
+	MatchExpired      = "expired"
+	MatchDeleted      = "deleted"
+	MatchPriceChanged = "price_changed"
+)
+
+type Match struct {
+	SessionID   string   `json:"session_id"`
+	OwnerID     string   `json:"user_id,omitempty"`
+	ProductID   string   `json:"product_id"`
+	ProductName string   `json:"product_name"`
+	SourceID    int64    `json:"source_id"`
+	MessageID   int      `json:"message_id"`
+	Text        string   `json:"text"`
+	Links       []string `json:"links"`
+	Store       string   `json:"store"`
+	OfferID     string   `json:"offer_id"`
+	Coupons     []Coupon `json:"coupons"`
+	Price       float64  `json:"price,omitempty"`
+	// HistoricalLow and BelowAverage compare Price with the price history.
+	HistoricalLow bool   `json:"historical_low"`
+	BelowAverage  bool   `json:"below_average"`
+	PostedAt      string `json:"posted_at"`
+	// Status is one of the Match* constants; empty is MatchActive.
+	Status        string  `json:"status,omitempty"`
+	PreviousPrice float64 `json:"previous_price,omitempty"`
+	EditedAt      string  `json:"edited_at,omitempty"`
+	// Account is the phone of the account that read the post; in legacy
+	// groups MessageID is only valid for it.
+	Account string `json:"account"`
+}
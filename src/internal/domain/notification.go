@@ -0,0 +1,17 @@
+package domain
+
+// Tipos de NotificationChannel.
+const (
+	ChannelWebhook  = "webhook"
+	ChannelTelegram = "telegram"
+)
+
+// NotificationChannel is where an owner wants to receive its matches.
+type NotificationChannel struct {
+	ID      string `json:"id"`
+	OwnerID string `json:"user_id"`
+	Kind    string `json:"kind"`
+	// Target is the webhook URL or the Telegram chat ID.
+	Target  string `json:"target"`
+	Enabled bool   `json:"enabled"`
+}
@@ -0,0 +1,21 @@
+package domain
+
+// PricePoint is a price seen in a match.
+type PricePoint struct {
+	ProductID  string  `json:"product_id"`
+	OfferID    string  `json:"offer_id"`
+	Store      string  `json:"store"`
+	Price      float64 `json:"price"`
+	SourceID   int64   `json:"source_id"`
+	MessageID  int     `json:"message_id"`
+	RecordedAt string  `json:"recorded_at"`
+}
+
+// PriceStats summarizes the prices recorded before a new one.
+type PriceStats struct {
+	Count  int
+	Lowest float64
+	// Count30 and Average30 cover the last 30 days.
+	Count30   int
+	Average30 float64
+}
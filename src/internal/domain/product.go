@@ -2,6 +2,7 @@ package domain
 
 type Product struct {
 	ProductID string `json:"id"`
+	OwnerID   string `json:"user_id"`
 	Title     string `json:"title"`
 	Name      string `json:"name"`
 	CreatedAt string `json:"created_at"`
@@ -0,0 +1,15 @@
+package domain
+
+// Provider is a Telegram channel or group where promotions are searched.
+// ProviderID is the Bot API style peer ID (-100<channel> or -<chat>).
+type Provider struct {
+	ProviderID        string `json:"id"`
+	Kind              string `json:"kind"`
+	Title             string `json:"title"`
+	Username          string `json:"username"`
+	Description       string `json:"description"`
+	ParticipantsCount int    `json:"participants_count"`
+	Broadcast         bool   `json:"broadcast"`
+	Megagroup         bool   `json:"megagroup"`
+	UpdatedAt         string `json:"updated_at"`
+}
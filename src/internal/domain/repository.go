@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned by StateRepository.GetState when the key is unset.
+var ErrNotFound = errors.New("not found")
+
+type SessionRepository interface {
+	ListSessions(ctx context.Context) ([]Session, error)
+}
+
+type ProductRepository interface {
+	// ListProducts returns the products of owner with the given IDs, or every
+	// product of owner when ids is empty. An empty owner has no products.
+	ListProducts(ctx context.Context, owner string, ids []string) ([]Product, error)
+}
+
+type NotificationRepository interface {
+	// ListChannels returns the enabled notification channels of owner.
+	ListChannels(ctx context.Context, owner string) ([]NotificationChannel, error)
+}
+
+type MatchRepository interface {
+	SaveMatch(ctx context.Context, match Match) error
+}
+
+// MatchTracker follows the posts of the matches after they are saved.
+type MatchTracker interface {
+	// ListTrackedMatches returns the matches posted since since (RFC 3339)
+	// that are still active or had the price changed.
+	ListTrackedMatches(ctx context.Context, since string) ([]Match, error)
+	// UpdateMatch stores the status, text, price, previous price and edit
+	// time of the match with the same session, product, source and message.
+	UpdateMatch(ctx context.Context, match Match) error
+}
+
+type ProviderRepository interface {
+	UpsertProviders(ctx context.Context, providers []Provider) error
+}
+
+type PriceRepository interface {
+	// PriceStats summarizes the prices recorded for the offer of point: the
+	// same OfferID or, when it is empty, the same product and store.
+	PriceStats(ctx context.Context, point PricePoint) (PriceStats, error)
+	RecordPrice(ctx context.Context, point PricePoint) error
+}
+
+type RunRepository interface {
+	SaveRun(ctx context.Context, run Run) error
+}
+
+// StateRepository keeps small values the bot needs between runs, like read
+// cursors.
+type StateRepository interface {
+	GetState(ctx context.Context, key string) ([]byte, error)
+	SetState(ctx context.Context, key string, value []byte) error
+}
+
+// Repository is everything the bot stores.
+type Repository interface {
+	SessionRepository
+	ProductRepository
+	MatchRepository
+	MatchTracker
+	NotificationRepository
+	ProviderRepository
+	PriceRepository
+	RunRepository
+	StateRepository
+}
@@ -0,0 +1,9 @@
+package domain
+
+// Run is one pass of the pipeline over every session.
+type Run struct {
+	StartedAt  string `json:"started_at"`
+	FinishedAt string `json:"finished_at"`
+	Matches    int    `json:"matches"`
+	Error      string `json:"error"`
+}
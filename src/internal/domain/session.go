@@ -2,9 +2,14 @@ package domain
 
 type Session struct {
 	SessionId    string   `json:"id"`
+	OwnerID      string   `json:"user_id"`
 	CronSchedule string   `json:"cron_schedule"`
+	Folder       string   `json:"folder"`
 	ProviderIds  []string `json:"provider_ids"`
 	ProductIds   []string `json:"product_ids"`
-	CreatedAt    string   `json:"created_at"`
-	UpdatedAt    string   `json:"updated_at"`
+	Stores       []string `json:"stores"`
+	// WatchCoupons reports every coupon posted, even without a product match.
+	WatchCoupons bool   `json:"watch_coupons"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
 }
@@ -0,0 +1,83 @@
+// Package api expõe os endpoints HTTP do backend.
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-faster/errors"
+)
+
+type Server struct {
+	mux   *http.ServeMux
+	srv   *http.Server
+	token string
+}
+
+// NewServerFromEnv creates the server listening on API_ADDR. It returns nil
+// when API_ADDR is not set. Every request must carry API_TOKEN as a bearer
+// token, since the endpoints can log in the Telegram account.
+func NewServerFromEnv() (*Server, error) {
+	addr := os.Getenv("API_ADDR")
+	if addr == "" {
+		return nil, nil
+	}
+
+	token := os.Getenv("API_TOKEN")
+	if token == "" {
+		return nil, errors.New("[API] API_TOKEN is required when API_ADDR is set")
+	}
+
+	return NewServer(addr, token), nil
+}
+
+func NewServer(addr, token string) *Server {
+	s := &Server{mux: http.NewServeMux(), token: token}
+	s.srv = &http.Server{
+		Addr:              addr,
+		Handler:           s.authorize(s.mux),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s
+}
+
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+func (s *Server) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	s.mux.HandleFunc(pattern, handler)
+}
+
+// Run serves until ctx is cancelled.
+func (s *Server) Run(ctx context.Context) error {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- s.srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		return errors.Wrap(err, "[API] listen")
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return s.srv.Shutdown(shutdownCtx)
+	}
+}
+
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Só pelo header: token na URL acaba nos logs de acesso e de proxy.
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
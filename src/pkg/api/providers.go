@@ -0,0 +1,55 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"bot-telegram/src/internal/domain"
+)
+
+type JoinFunc func(ctx context.Context, link string) (domain.Provider, error)
+
+type joinRequest struct {
+	Link string `json:"link"`
+}
+
+// JoinHandler joins the channel in the "link" field (@username or t.me link)
+// and responds with the provider created for it.
+func JoinHandler(join JoinFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req joinRequest
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "invalid JSON body", http.StatusBadRequest)
+				return
+			}
+		} else {
+			req.Link = r.FormValue("link")
+		}
+
+		if strings.TrimSpace(req.Link) == "" {
+			http.Error(w, "missing link", http.StatusBadRequest)
+			return
+		}
+
+		provider, err := join(r.Context(), req.Link)
+		if err != nil {
+			log.Printf("[API] join %s: %v", req.Link, err)
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, provider)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("[API] encode response: %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+// Package catalog sincroniza os canais e grupos das contas com a tabela providers.
+package catalog
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"bot-telegram/src/internal/domain"
+	"bot-telegram/src/pkg/telegram"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/tg"
+)
+
+type Store = domain.ProviderRepository
+
+type StoreFunc func(ctx context.Context, providers []domain.Provider) error
+
+func (f StoreFunc) UpsertProviders(ctx context.Context, providers []domain.Provider) error {
+	return f(ctx, providers)
+}
+
+// interval spaces the full channel requests, which are heavily rate limited.
+const interval = 300 * time.Millisecond
+
+// Sync enumerates the dialogs of every account in the pool and upserts the
+// metadata of each channel, supergroup and group. Sources that fail to be
+// described are logged and skipped. It returns how many were stored.
+func Sync(ctx context.Context, pool *telegram.Pool, store Store) (int, error) {
+	sources, err := pool.ListDialogSources(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "[CATALOG] list dialogs")
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	providers := make([]domain.Provider, 0, len(sources))
+	for _, source := range sources {
+		err := pool.Do(ctx, source.ID, func(ctx context.Context, raw *tg.Client, source telegram.Source) error {
+			provider, err := telegram.DescribeProvider(ctx, raw, source)
+			if err != nil {
+				return err
+			}
+			providers = append(providers, provider)
+			return nil
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return 0, ctx.Err()
+			}
+			log.Printf("[CATALOG] skip source %d: %v", source.ID, err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+
+	if len(providers) == 0 {
+		return 0, nil
+	}
+	if err := store.UpsertProviders(ctx, providers); err != nil {
+		return 0, errors.Wrap(err, "[CATALOG] store providers")
+	}
+
+	return len(providers), nil
+}
+
+// Join joins the channel or group referenced by link, adds it to folder and
+// records it as a provider. When only adding it to the folder fails, the
+// provider is still recorded and the folder error is returned.
+func Join(ctx context.Context, pool *telegram.Pool, store Store, link, folder string) (domain.Provider, error) {
+	source, joinErr := pool.Join(ctx, link, folder)
+	if joinErr != nil && source.ID == 0 {
+		return domain.Provider{}, errors.Wrap(joinErr, "[CATALOG] join")
+	}
+
+	var provider domain.Provider
+	err := pool.Do(ctx, source.ID, func(ctx context.Context, raw *tg.Client, source telegram.Source) error {
+		var err error
+		provider, err = telegram.DescribeProvider(ctx, raw, source)
+		return err
+	})
+	if err != nil {
+		return domain.Provider{}, errors.Wrap(err, "[CATALOG] describe joined source")
+	}
+
+	if err := store.UpsertProviders(ctx, []domain.Provider{provider}); err != nil {
+		return domain.Provider{}, errors.Wrap(err, "[CATALOG] store provider")
+	}
+	if joinErr != nil {
+		return provider, errors.Wrap(joinErr, "[CATALOG] join")
+	}
+
+	return provider, nil
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"os"
+	"strings"
+
+	"github.com/go-faster/errors"
+)
+
+// Secret returns the value of the environment variable name. When it is
+// empty, the value is read from the file pointed by name + "_FILE", which is
+// how Docker and Kubernetes secrets are usually mounted.
+func Secret(name string) (string, error) {
+	if value := os.Getenv(name); value != "" {
+		return value, nil
+	}
+
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "read %s_FILE", name)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
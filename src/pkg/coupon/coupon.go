@@ -0,0 +1,116 @@
+// Package coupon encontra os cupons de desconto nas mensagens de promoção.
+package coupon
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"bot-telegram/src/internal/domain"
+
+	"github.com/gotd/td/tg"
+)
+
+var (
+	// "CUPOM: PROMO10", "use o código BLACK50", "cupom de desconto “FRETE”".
+	keywordPattern = regexp.MustCompile(`(?i:cupo[mn]s?|c[oó]digo|code)(?i:\s+de\s+desconto)?(?i:\s+(?:é|e|use|usando))?\s*[:：=\-–]?\s*["“'‘]?([A-Z0-9][A-Z0-9_\-]{2,24})\b`)
+	codePattern    = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_\-]{2,24}$`)
+
+	discountPattern = regexp.MustCompile(`(?i)(\d{1,2}(?:[.,]\d+)?\s*%)|(R\$\s*\d+(?:[.,]\d{2})?)\s*(?:off|de\s+desconto)`)
+	expiresPattern  = regexp.MustCompile(`(?i)(?:v[aá]lido|validade|expira|termina|v[aá]lida)\s*(?:at[eé]|em|:)?\s*(\d{1,2}/\d{1,2}(?:/\d{2,4})?|hoje|amanh[ãa]|\d{1,2}h(?:\d{2})?)`)
+)
+
+// Extract returns the coupons of message: code and pre entities that look like
+// a coupon and codes after "cupom"/"código". The discount and the expiry are
+// taken from the line of the code or, failing that, from the whole message.
+func Extract(message *tg.Message) []domain.Coupon {
+	var (
+		coupons []domain.Coupon
+		seen    = make(map[string]bool)
+		text    = message.Message
+	)
+	add := func(code string, at int) {
+		code = strings.Trim(code, "-_")
+		if !looksLikeCode(code) || seen[strings.ToUpper(code)] {
+			return
+		}
+		seen[strings.ToUpper(code)] = true
+
+		line := lineAt(text, at)
+		coupons = append(coupons, domain.Coupon{
+			Code:     code,
+			Discount: firstMatch(discountPattern, line, text),
+			Expires:  firstMatch(expiresPattern, line, text),
+		})
+	}
+
+	units := utf16.Encode([]rune(text))
+	for _, entity := range message.Entities {
+		var offset, length int
+		switch e := entity.(type) {
+		case *tg.MessageEntityCode:
+			offset, length = e.Offset, e.Length
+		case *tg.MessageEntityPre:
+			offset, length = e.Offset, e.Length
+		default:
+			continue
+		}
+		if offset < 0 || length <= 0 || offset+length > len(units) {
+			continue
+		}
+		code := strings.TrimSpace(string(utf16.Decode(units[offset : offset+length])))
+		add(code, len(string(utf16.Decode(units[:offset]))))
+	}
+
+	for _, m := range keywordPattern.FindAllStringSubmatchIndex(text, -1) {
+		add(text[m[2]:m[3]], m[2])
+	}
+
+	return coupons
+}
+
+// looksLikeCode descarta palavras comuns: o código precisa ter um dígito ou
+// estar todo em maiúsculas. Só dígitos no tamanho de um EAN/UPC/GTIN
+// ("Código: 7891234567890") é o código de barras do produto.
+func looksLikeCode(code string) bool {
+	if !codePattern.MatchString(code) {
+		return false
+	}
+	digits, hasLower := 0, false
+	for _, r := range code {
+		if unicode.IsDigit(r) {
+			digits++
+		}
+		hasLower = hasLower || unicode.IsLower(r)
+	}
+	if digits == len(code) {
+		switch digits {
+		case 8, 12, 13, 14:
+			return false
+		}
+	}
+	return digits > 0 || !hasLower
+}
+
+func lineAt(text string, at int) string {
+	start := strings.LastIndex(text[:at], "\n") + 1
+	end := strings.Index(text[at:], "\n")
+	if end < 0 {
+		return text[start:]
+	}
+	return text[start : at+end]
+}
+
+func firstMatch(re *regexp.Regexp, texts ...string) string {
+	for _, text := range texts {
+		if m := re.FindStringSubmatch(text); m != nil {
+			for _, group := range m[1:] {
+				if group != "" {
+					return strings.Join(strings.Fields(group), " ")
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,78 @@
+package coupon
+
+import (
+	"reflect"
+	"testing"
+
+	"bot-telegram/src/internal/domain"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		entities []tg.MessageEntityClass
+		want     []domain.Coupon
+	}{
+		{
+			name: "keyword",
+			text: "Use o CUPOM: PROMO10 para 10% OFF",
+			want: []domain.Coupon{{Code: "PROMO10", Discount: "10%"}},
+		},
+		{
+			name: "code entity",
+			text: "Cupom Amazon\nBLACK50\nválido até 30/11",
+			entities: []tg.MessageEntityClass{
+				&tg.MessageEntityCode{Offset: 13, Length: 7},
+			},
+			want: []domain.Coupon{{Code: "BLACK50", Expires: "30/11"}},
+		},
+		{
+			name: "entity and keyword are the same coupon",
+			text: "Cupom: FRETE20",
+			entities: []tg.MessageEntityClass{
+				&tg.MessageEntityCode{Offset: 7, Length: 7},
+			},
+			want: []domain.Coupon{{Code: "FRETE20"}},
+		},
+		{
+			name: "discount of the line",
+			text: "código SSD15 dá R$ 15 de desconto\ncódigo TV10 dá 10%",
+			want: []domain.Coupon{
+				{Code: "SSD15", Discount: "R$ 15"},
+				{Code: "TV10", Discount: "10%"},
+			},
+		},
+		{
+			name: "common word",
+			text: "Cupom de desconto no app",
+		},
+		{
+			name: "EAN",
+			text: "Código: 7891234567890",
+		},
+		{
+			name: "UPC in a code entity",
+			text: "012345678905",
+			entities: []tg.MessageEntityClass{
+				&tg.MessageEntityCode{Offset: 0, Length: 12},
+			},
+		},
+		{
+			name: "numeric coupon",
+			text: "Cupom: 100100",
+			want: []domain.Coupon{{Code: "100100"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Extract(&tg.Message{Message: tt.text, Entities: tt.entities})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Extract(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+// Package followup acompanha os posts dos matches já gravados: preço editado,
+// oferta esgotada ou encerrada e post apagado atualizam o match e avisam quem
+// recebeu o alerta.
+package followup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"bot-telegram/src/internal/domain"
+	"bot-telegram/src/pkg/offer"
+	"bot-telegram/src/pkg/price"
+
+	"github.com/gotd/td/tg"
+)
+
+type MessageLookup interface {
+	// LookupMessages returns the messages of source by ID, read with account
+	// (the one that read the matches); the deleted ones are *tg.MessageEmpty
+	// or missing from the map.
+	LookupMessages(ctx context.Context, sourceID int64, account string, ids []int) (map[int]tg.MessageClass, error)
+}
+
+type MessageLookupFunc func(ctx context.Context, sourceID int64, account string, ids []int) (map[int]tg.MessageClass, error)
+
+func (f MessageLookupFunc) LookupMessages(ctx context.Context, sourceID int64, account string, ids []int) (map[int]tg.MessageClass, error) {
+	return f(ctx, sourceID, account, ids)
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, match domain.Match) error
+}
+
+type Checker struct {
+	Matches  domain.MatchTracker
+	Messages MessageLookup
+	// Notifier receives the matches whose status changed; optional.
+	Notifier Notifier
+	// Window is how long posts are followed. Defaults to 24 hours.
+	Window time.Duration
+}
+
+// lookupBatch is the most IDs Telegram accepts per request.
+const lookupBatch = 100
+
+// Check re-reads the posts of the tracked matches and updates the ones whose
+// status changed, returning how many. A source that fails is skipped and
+// reported in the error.
+func (c *Checker) Check(ctx context.Context) (int, error) {
+	window := c.Window
+	if window <= 0 {
+		window = 24 * time.Hour
+	}
+
+	matches, err := c.Matches.ListTrackedMatches(ctx, time.Now().Add(-window).UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, fmt.Errorf("list tracked matches: %w", err)
+	}
+
+	// Em grupos comuns os IDs são de cada conta: cada conta relê os seus.
+	bySource := make(map[sourceKey][]domain.Match)
+	for _, match := range matches {
+		key := sourceKey{match.SourceID, match.Account}
+		bySource[key] = append(bySource[key], match)
+	}
+
+	updated := 0
+	var errs []error
+	for key, matches := range bySource {
+		sourceID := key.id
+		messages, err := c.lookup(ctx, key, matches)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("source %d: %w", sourceID, err))
+			continue
+		}
+
+		for _, match := range matches {
+			next, changed := Update(match, messages[match.MessageID])
+			if !changed {
+				continue
+			}
+
+			if err := c.Matches.UpdateMatch(ctx, next); err != nil {
+				errs = append(errs, fmt.Errorf("update match %d/%d: %w", sourceID, match.MessageID, err))
+				continue
+			}
+			updated++
+
+			if c.Notifier != nil {
+				if err := c.Notifier.Notify(ctx, next); err != nil {
+					errs = append(errs, fmt.Errorf("notify match %d/%d: %w", sourceID, match.MessageID, err))
+				}
+			}
+		}
+	}
+
+	return updated, errors.Join(errs...)
+}
+
+type sourceKey struct {
+	id      int64
+	account string
+}
+
+func (c *Checker) lookup(ctx context.Context, source sourceKey, matches []domain.Match) (map[int]tg.MessageClass, error) {
+	seen := make(map[int]bool, len(matches))
+	var ids []int
+	for _, match := range matches {
+		if !seen[match.MessageID] {
+			seen[match.MessageID] = true
+			ids = append(ids, match.MessageID)
+		}
+	}
+
+	messages := make(map[int]tg.MessageClass, len(ids))
+	for start := 0; start < len(ids); start += lookupBatch {
+		batch := ids[start:min(start+lookupBatch, len(ids))]
+		found, err := c.Messages.LookupMessages(ctx, source.id, source.account, batch)
+		if err != nil {
+			return nil, err
+		}
+		for id, message := range found {
+			messages[id] = message
+		}
+	}
+	return messages, nil
+}
+
+// Update returns match with the status of its post (nil or
+// *tg.MessageEmpty when it was deleted) and whether the status or the price
+// changed. Edits that change neither are ignored, and so are service
+// messages.
+func Update(match domain.Match, found tg.MessageClass) (domain.Match, bool) {
+	var message *tg.Message
+	switch m := found.(type) {
+	case nil, *tg.MessageEmpty:
+		match.Status = domain.MatchDeleted
+		return match, true
+	case *tg.Message:
+		message = m
+	default:
+		return match, false
+	}
+
+	edit, ok := message.GetEditDate()
+	if !ok {
+		return match, false
+	}
+	editedAt := time.Unix(int64(edit), 0).UTC()
+	if last, err := time.Parse(time.RFC3339, match.EditedAt); err == nil && !editedAt.After(last) {
+		return match, false
+	}
+
+	next := match
+	next.Text = message.Message
+	next.EditedAt = editedAt.Format(time.RFC3339)
+
+	if status := offer.Classify(message); status.Ended() {
+		next.Status = status.MatchStatus()
+		return next, true
+	}
+	if value, ok := price.Extract(message.Message); ok && match.Price > 0 && value != match.Price {
+		next.Status = domain.MatchPriceChanged
+		next.PreviousPrice = match.Price
+		next.Price = value
+		return next, true
+	}
+
+	return match, false
+}
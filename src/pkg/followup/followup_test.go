@@ -0,0 +1,119 @@
+package followup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bot-telegram/src/internal/domain"
+	"bot-telegram/src/pkg/memory"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestUpdate(t *testing.T) {
+	posted := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	edited := func(text string, at time.Time) *tg.Message {
+		m := &tg.Message{ID: 7, Message: text}
+		m.SetEditDate(int(at.Unix()))
+		return m
+	}
+
+	match := domain.Match{
+		MessageID: 7,
+		Text:      "SSD 1TB por R$ 299",
+		Price:     299,
+		PostedAt:  posted.Format(time.RFC3339),
+		Status:    domain.MatchActive,
+	}
+	seenEdit := match
+	seenEdit.EditedAt = posted.Add(time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		name        string
+		match       domain.Match
+		message     tg.MessageClass
+		wantChanged bool
+		wantStatus  string
+		wantPrice   float64
+	}{
+		{name: "missing", match: match, message: nil, wantChanged: true, wantStatus: domain.MatchDeleted, wantPrice: 299},
+		{name: "empty", match: match, message: &tg.MessageEmpty{ID: 7}, wantChanged: true, wantStatus: domain.MatchDeleted, wantPrice: 299},
+		{name: "service", match: match, message: &tg.MessageService{ID: 7}, wantStatus: domain.MatchActive, wantPrice: 299},
+		{name: "not edited", match: match, message: &tg.Message{ID: 7, Message: match.Text}, wantStatus: domain.MatchActive, wantPrice: 299},
+		{
+			name: "sold out", match: match, message: edited("ESGOTADO\nSSD 1TB por R$ 299", posted.Add(time.Hour)),
+			wantChanged: true, wantStatus: domain.MatchSoldOut, wantPrice: 299,
+		},
+		{
+			name: "expired", match: match, message: edited("Promoção encerrada", posted.Add(time.Hour)),
+			wantChanged: true, wantStatus: domain.MatchExpired, wantPrice: 299,
+		},
+		{
+			name: "price changed", match: match, message: edited("SSD 1TB por R$ 349", posted.Add(time.Hour)),
+			wantChanged: true, wantStatus: domain.MatchPriceChanged, wantPrice: 349,
+		},
+		{
+			name: "same price", match: match, message: edited("SSD 1TB por R$ 299 🔥", posted.Add(time.Hour)),
+			wantStatus: domain.MatchActive, wantPrice: 299,
+		},
+		{
+			name: "edit already seen", match: seenEdit, message: edited("ESGOTADO", posted.Add(time.Hour)),
+			wantStatus: domain.MatchActive, wantPrice: 299,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := Update(tt.match, tt.message)
+			if changed != tt.wantChanged || got.Status != tt.wantStatus || got.Price != tt.wantPrice {
+				t.Errorf("Update() = %s %v, %v; want %s %v, %v", got.Status, got.Price, changed, tt.wantStatus, tt.wantPrice, tt.wantChanged)
+			}
+			if tt.wantStatus == domain.MatchPriceChanged && got.PreviousPrice != tt.match.Price {
+				t.Errorf("PreviousPrice = %v, want %v", got.PreviousPrice, tt.match.Price)
+			}
+		})
+	}
+}
+
+func TestCheckLooksUpWithTheAccountOfTheMatch(t *testing.T) {
+	ctx := context.Background()
+	repo := &memory.Repository{}
+
+	// Grupo comum: a mensagem 7 de cada conta é um post diferente.
+	const group = -42
+	posted := time.Now().UTC().Format(time.RFC3339)
+	for _, account := range []string{"+5511", "+5522"} {
+		match := domain.Match{SessionID: "s1", ProductID: "ssd", SourceID: group, Account: account, MessageID: 7, Text: "SSD 1TB por R$ 299", Price: 299, PostedAt: posted, Status: domain.MatchActive}
+		if err := repo.SaveMatch(ctx, match); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checker := &Checker{
+		Matches: repo,
+		Messages: MessageLookupFunc(func(ctx context.Context, sourceID int64, account string, ids []int) (map[int]tg.MessageClass, error) {
+			if account == "+5511" {
+				return map[int]tg.MessageClass{7: &tg.Message{ID: 7, Message: "SSD 1TB por R$ 299"}}, nil
+			}
+			return map[int]tg.MessageClass{}, nil
+		}),
+	}
+	updated, err := checker.Check(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated != 1 {
+		t.Errorf("Check() = %d, want 1", updated)
+	}
+
+	for _, match := range repo.Matches() {
+		want := domain.MatchActive
+		if match.Account == "+5522" {
+			want = domain.MatchDeleted
+		}
+		if match.Status != want {
+			t.Errorf("match of %s: status %q, want %q", match.Account, match.Status, want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Package links extrai as URLs das mensagens de promoção e expande os
+// encurtadores para chegar no link real da oferta.
+package links
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/gotd/td/tg"
+)
+
+var urlPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"']+`)
+
+// Extract returns the URLs of message in the order they appear: URL and
+// text-URL entities, URLs in the text not marked as entities, then inline
+// buttons. Duplicates are dropped.
+func Extract(message *tg.Message) []string {
+	var (
+		found []string
+		seen  = make(map[string]bool)
+	)
+	add := func(raw string) {
+		u, ok := normalize(raw)
+		if ok && !seen[u] {
+			seen[u] = true
+			found = append(found, u)
+		}
+	}
+
+	text := utf16.Encode([]rune(message.Message))
+	for _, entity := range message.Entities {
+		switch e := entity.(type) {
+		case *tg.MessageEntityURL:
+			add(entitySlice(text, e.Offset, e.Length))
+		case *tg.MessageEntityTextURL:
+			add(e.URL)
+		}
+	}
+
+	for _, raw := range urlPattern.FindAllString(message.Message, -1) {
+		add(raw)
+	}
+
+	if markup, ok := message.ReplyMarkup.(*tg.ReplyInlineMarkup); ok {
+		for _, row := range markup.Rows {
+			for _, button := range row.Buttons {
+				switch b := button.(type) {
+				case *tg.KeyboardButtonURL:
+					add(b.URL)
+				case *tg.KeyboardButtonURLAuth:
+					add(b.URL)
+				}
+			}
+		}
+	}
+
+	return found
+}
+
+// entitySlice cuts text at the entity offsets, which Telegram counts in UTF-16
+// code units.
+func entitySlice(text []uint16, offset, length int) string {
+	if offset < 0 || length <= 0 || offset+length > len(text) {
+		return ""
+	}
+	return string(utf16.Decode(text[offset : offset+length]))
+}
+
+// normalize adds the missing scheme, trims the punctuation that usually
+// follows a link in the text and drops anything that is not http(s).
+func normalize(raw string) (string, bool) {
+	raw = strings.TrimRight(strings.TrimSpace(raw), ".,;:!?)]}*_")
+	if raw == "" {
+		return "", false
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", false
+	}
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+
+	return u.String(), true
+}
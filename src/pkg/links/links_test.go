@@ -0,0 +1,77 @@
+package links
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name    string
+		message *tg.Message
+		want    []string
+	}{
+		{
+			name:    "plain text",
+			message: &tg.Message{Message: "SSD em https://amzn.to/3abc. Corre!"},
+			want:    []string{"https://amzn.to/3abc"},
+		},
+		{
+			name:    "without scheme",
+			message: &tg.Message{Message: "Link: www.KaBuM.com.br/produto/123 (frete grátis)"},
+			want:    []string{"https://www.kabum.com.br/produto/123"},
+		},
+		{
+			name: "entities after emoji",
+			message: &tg.Message{
+				Message: "🔥 Oferta aqui",
+				Entities: []tg.MessageEntityClass{
+					&tg.MessageEntityTextURL{Offset: 3, Length: 6, URL: "https://shope.ee/abc"},
+				},
+			},
+			want: []string{"https://shope.ee/abc"},
+		},
+		{
+			name: "url entity counts UTF-16 units",
+			message: &tg.Message{
+				Message: "🔥 https://meli.la/xyz",
+				Entities: []tg.MessageEntityClass{
+					&tg.MessageEntityURL{Offset: 3, Length: 19},
+				},
+			},
+			want: []string{"https://meli.la/xyz"},
+		},
+		{
+			name: "buttons and duplicates",
+			message: &tg.Message{
+				Message: "https://amzn.to/3abc",
+				ReplyMarkup: &tg.ReplyInlineMarkup{Rows: []tg.KeyboardButtonRow{{
+					Buttons: []tg.KeyboardButtonClass{
+						&tg.KeyboardButtonURL{Text: "Comprar", URL: "https://amzn.to/3abc"},
+						&tg.KeyboardButtonURL{Text: "Cupom", URL: "https://amzn.to/cupom#top"},
+					},
+				}}},
+			},
+			want: []string{"https://amzn.to/3abc", "https://amzn.to/cupom"},
+		},
+		{
+			name: "not http",
+			message: &tg.Message{
+				Message: "tg://resolve?domain=canal",
+				Entities: []tg.MessageEntityClass{
+					&tg.MessageEntityTextURL{Offset: 0, Length: 5, URL: "tg://resolve?domain=canal"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Extract(tt.message); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Extract() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
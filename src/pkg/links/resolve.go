@@ -0,0 +1,175 @@
+package links
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-faster/errors"
+)
+
+// DefaultShorteners are the hosts whose links are expanded by Resolver.
+var DefaultShorteners = []string{
+	"amzn.to", "a.co", "bit.ly", "tinyurl.com", "cutt.ly", "is.gd", "t.co",
+	"shope.ee", "s.shopee.com.br", "mercadolivre.com", "meli.la", "magalu.lu",
+	"divulgador.magalu.com", "s.click.aliexpress.com", "tidd.ly", "compre.vc",
+}
+
+// Resolver expands shortened links by following their redirects.
+type Resolver struct {
+	// Client does the requests; its CheckRedirect is not used, redirects are
+	// followed by Resolve. Defaults to http.DefaultClient.
+	Client *http.Client
+	// Shorteners are the hosts to expand; other links are returned as they
+	// are. Defaults to DefaultShorteners.
+	Shorteners []string
+	// MaxHops limits the redirects followed. Defaults to 5.
+	MaxHops int
+	// CacheSize limits the expanded links kept in memory. Defaults to 10000.
+	CacheSize int
+	// CacheTTL is how long an expanded link is kept. Defaults to 24 hours.
+	CacheTTL time.Duration
+
+	once  sync.Once
+	hosts map[string]bool
+
+	mu    sync.Mutex
+	cache map[string]cached
+}
+
+type cached struct {
+	link    string
+	expires time.Time
+}
+
+// Resolve returns the URL link points to. Links from hosts that are not
+// shorteners are returned unchanged.
+func (r *Resolver) Resolve(ctx context.Context, link string) (string, error) {
+	r.once.Do(r.init)
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return link, errors.Wrap(err, "[LINKS] parse")
+	}
+	if !r.hosts[strings.ToLower(u.Hostname())] {
+		return link, nil
+	}
+	if canonical, ok := r.load(link); ok {
+		return canonical, nil
+	}
+
+	client := *r.Client
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+
+	current := link
+	for hop := 0; hop < r.MaxHops; hop++ {
+		next, err := r.next(ctx, &client, current)
+		if err != nil {
+			return link, err
+		}
+		if next == "" {
+			break
+		}
+		current = next
+
+		u, err := url.Parse(current)
+		if err != nil || !r.hosts[strings.ToLower(u.Hostname())] {
+			break
+		}
+	}
+
+	canonical, ok := normalize(current)
+	if !ok {
+		return link, errors.Errorf("[LINKS] %s redirects to invalid URL %q", link, current)
+	}
+	r.store(link, canonical)
+
+	return canonical, nil
+}
+
+func (r *Resolver) load(link string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.cache[link]
+	if !ok || time.Now().After(entry.expires) {
+		return "", false
+	}
+	return entry.link, true
+}
+
+// store guarda link; cheio, descarta os vencidos e, se ainda não couber,
+// qualquer outro.
+func (r *Resolver) store(link, canonical string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if _, ok := r.cache[link]; !ok && len(r.cache) >= r.CacheSize {
+		for key, entry := range r.cache {
+			if now.After(entry.expires) {
+				delete(r.cache, key)
+			}
+		}
+		for key := range r.cache {
+			if len(r.cache) < r.CacheSize {
+				break
+			}
+			delete(r.cache, key)
+		}
+	}
+	r.cache[link] = cached{link: canonical, expires: now.Add(r.CacheTTL)}
+}
+
+// next returns the Location of link, or "" when it does not redirect.
+func (r *Resolver) next(ctx context.Context, client *http.Client, link string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "[LINKS] request")
+	}
+	// Alguns encurtadores devolvem 403 para clientes sem User-Agent de navegador.
+	req.Header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64)")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", errors.Wrapf(err, "[LINKS] resolve %s", link)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 300 || resp.StatusCode >= 400 {
+		return "", nil
+	}
+	location, err := resp.Location()
+	if err != nil {
+		return "", errors.Wrapf(err, "[LINKS] %s redirect", link)
+	}
+
+	return location.String(), nil
+}
+
+func (r *Resolver) init() {
+	if r.Client == nil {
+		r.Client = http.DefaultClient
+	}
+	if r.Shorteners == nil {
+		r.Shorteners = DefaultShorteners
+	}
+	if r.MaxHops <= 0 {
+		r.MaxHops = 5
+	}
+	if r.CacheSize <= 0 {
+		r.CacheSize = 10000
+	}
+	if r.CacheTTL <= 0 {
+		r.CacheTTL = 24 * time.Hour
+	}
+	r.cache = make(map[string]cached)
+
+	r.hosts = make(map[string]bool, len(r.Shorteners))
+	for _, host := range r.Shorteners {
+		r.hosts[strings.ToLower(host)] = true
+	}
+}
@@ -0,0 +1,77 @@
+package links
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+)
+
+func TestResolver(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/a":
+			http.Redirect(w, r, "/b", http.StatusMovedPermanently)
+		case "/b":
+			http.Redirect(w, r, "https://www.Amazon.com.br/dp/B09B8VGCR8?tag=promo-20#reviews", http.StatusFound)
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	host, _ := url.Parse(server.URL)
+	r := &Resolver{Client: server.Client(), Shorteners: []string{host.Hostname()}, MaxHops: 3}
+	ctx := context.Background()
+
+	tests := []struct {
+		link     string
+		want     string
+		requests int32
+	}{
+		{server.URL + "/a", "https://www.amazon.com.br/dp/B09B8VGCR8?tag=promo-20", 2},
+		// Do cache.
+		{server.URL + "/a", "https://www.amazon.com.br/dp/B09B8VGCR8?tag=promo-20", 0},
+		{server.URL + "/final", server.URL + "/final", 1},
+		{server.URL + "/loop", server.URL + "/loop", 3},
+		{"https://www.kabum.com.br/produto/123", "https://www.kabum.com.br/produto/123", 0},
+	}
+
+	for _, tt := range tests {
+		requests.Store(0)
+		got, err := r.Resolve(ctx, tt.link)
+		if err != nil {
+			t.Errorf("Resolve(%q): %v", tt.link, err)
+			continue
+		}
+		if got != tt.want || requests.Load() != tt.requests {
+			t.Errorf("Resolve(%q) = %q with %d requests, want %q with %d", tt.link, got, requests.Load(), tt.want, tt.requests)
+		}
+	}
+}
+
+func TestResolverCacheSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	host, _ := url.Parse(server.URL)
+	r := &Resolver{Client: server.Client(), Shorteners: []string{host.Hostname()}, CacheSize: 2}
+	for _, path := range []string{"/1", "/2", "/3", "/4"} {
+		if _, err := r.Resolve(context.Background(), server.URL+path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(r.cache) != 2 {
+		t.Errorf("cache has %d links, want 2", len(r.cache))
+	}
+	if _, ok := r.load(server.URL + "/4"); !ok {
+		t.Error("last link is not cached")
+	}
+}
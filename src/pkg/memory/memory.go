@@ -0,0 +1,268 @@
+// Package memory guarda tudo em memória, para testes e para rodar o bot sem
+// um projeto no Supabase.
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"bot-telegram/src/internal/domain"
+
+	"github.com/go-faster/errors"
+)
+
+// Repository implements domain.Repository in memory. The zero value is empty
+// and ready to use.
+type Repository struct {
+	mu        sync.Mutex
+	sessions  []domain.Session
+	products  []domain.Product
+	channels  []domain.NotificationChannel
+	matches   []domain.Match
+	providers map[string]domain.Provider
+	prices    []domain.PricePoint
+	runs      []domain.Run
+	state     map[string][]byte
+}
+
+var _ domain.Repository = (*Repository)(nil)
+
+// Seed is the content of the file read by Load.
+type Seed struct {
+	Sessions []domain.Session             `json:"sessions"`
+	Products []domain.Product             `json:"products"`
+	Channels []domain.NotificationChannel `json:"notification_channels"`
+}
+
+// Load returns a repository with the sessions and products of the JSON file
+// at path.
+func Load(path string) (*Repository, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "[MEMORY] read seed")
+	}
+
+	var seed Seed
+	if err := json.Unmarshal(data, &seed); err != nil {
+		return nil, errors.Wrap(err, "[MEMORY] parse seed")
+	}
+
+	return &Repository{sessions: seed.Sessions, products: seed.Products, channels: seed.Channels}, nil
+}
+
+func (r *Repository) AddSession(session domain.Session) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sessions = append(r.sessions, session)
+}
+
+func (r *Repository) AddProduct(product domain.Product) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.products = append(r.products, product)
+}
+
+// Matches returns the matches saved so far.
+func (r *Repository) Matches() []domain.Match {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.matches)
+}
+
+// Providers returns the providers upserted so far.
+func (r *Repository) Providers() []domain.Provider {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	providers := make([]domain.Provider, 0, len(r.providers))
+	for _, provider := range r.providers {
+		providers = append(providers, provider)
+	}
+	return providers
+}
+
+func (r *Repository) ListSessions(ctx context.Context) ([]domain.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.sessions), nil
+}
+
+func (r *Repository) AddChannel(channel domain.NotificationChannel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.channels = append(r.channels, channel)
+}
+
+func (r *Repository) ListProducts(ctx context.Context, owner string, ids []string) ([]domain.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var products []domain.Product
+	for _, product := range r.products {
+		if owner == "" || product.OwnerID != owner {
+			continue
+		}
+		if len(ids) == 0 || slices.Contains(ids, product.ProductID) {
+			products = append(products, product)
+		}
+	}
+	return products, nil
+}
+
+func (r *Repository) ListChannels(ctx context.Context, owner string) ([]domain.NotificationChannel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var channels []domain.NotificationChannel
+	for _, channel := range r.channels {
+		if channel.OwnerID == owner && channel.Enabled {
+			channels = append(channels, channel)
+		}
+	}
+	return channels, nil
+}
+
+func (r *Repository) SaveMatch(ctx context.Context, match domain.Match) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.matches = append(r.matches, match)
+	return nil
+}
+
+func (r *Repository) ListTrackedMatches(ctx context.Context, since string) ([]domain.Match, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var matches []domain.Match
+	for _, match := range r.matches {
+		if match.PostedAt >= since && tracked(match.Status) {
+			matches = append(matches, match)
+		}
+	}
+	return matches, nil
+}
+
+func (r *Repository) UpdateMatch(ctx context.Context, match domain.Match) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, m := range r.matches {
+		if m.SessionID == match.SessionID && m.ProductID == match.ProductID &&
+			m.SourceID == match.SourceID && m.Account == match.Account && m.MessageID == match.MessageID {
+			r.matches[i].Status = match.Status
+			r.matches[i].Text = match.Text
+			r.matches[i].Price = match.Price
+			r.matches[i].PreviousPrice = match.PreviousPrice
+			r.matches[i].EditedAt = match.EditedAt
+		}
+	}
+	return nil
+}
+
+func tracked(status string) bool {
+	return status == "" || status == domain.MatchActive || status == domain.MatchPriceChanged
+}
+
+func (r *Repository) UpsertProviders(ctx context.Context, providers []domain.Provider) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.providers == nil {
+		r.providers = make(map[string]domain.Provider, len(providers))
+	}
+	for _, provider := range providers {
+		r.providers[provider.ProviderID] = provider
+	}
+	return nil
+}
+
+func (r *Repository) RecordPrice(ctx context.Context, point domain.PricePoint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.prices = append(r.prices, point)
+	return nil
+}
+
+func (r *Repository) PriceStats(ctx context.Context, point domain.PricePoint) (domain.PriceStats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var stats domain.PriceStats
+	since := time.Now().AddDate(0, 0, -30)
+	for _, p := range r.prices {
+		if !sameOffer(p, point) {
+			continue
+		}
+
+		if stats.Count == 0 || p.Price < stats.Lowest {
+			stats.Lowest = p.Price
+		}
+		stats.Count++
+
+		if at, err := time.Parse(time.RFC3339, p.RecordedAt); err == nil && at.After(since) {
+			stats.Average30 += p.Price
+			stats.Count30++
+		}
+	}
+	if stats.Count30 > 0 {
+		stats.Average30 /= float64(stats.Count30)
+	}
+
+	return stats, nil
+}
+
+func sameOffer(a, b domain.PricePoint) bool {
+	if b.OfferID != "" {
+		return a.OfferID == b.OfferID
+	}
+	return a.ProductID == b.ProductID && a.Store == b.Store
+}
+
+func (r *Repository) SaveRun(ctx context.Context, run domain.Run) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.runs = append(r.runs, run)
+	return nil
+}
+
+// Runs returns the runs saved so far.
+func (r *Repository) Runs() []domain.Run {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.runs)
+}
+
+func (r *Repository) GetState(ctx context.Context, key string) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	value, ok := r.state[key]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return slices.Clone(value), nil
+}
+
+func (r *Repository) SetState(ctx context.Context, key string, value []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.state == nil {
+		r.state = make(map[string][]byte)
+	}
+	r.state[key] = slices.Clone(value)
+	return nil
+}
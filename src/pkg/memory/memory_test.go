@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bot-telegram/src/internal/domain"
+)
+
+func TestRepository(t *testing.T) {
+	ctx := context.Background()
+	var r domain.Repository = newSeeded()
+
+	products, err := r.ListProducts(ctx, "alice", nil)
+	if err != nil || len(products) != 2 {
+		t.Errorf("ListProducts(alice) = %+v, %v; want 2 products", products, err)
+	}
+	products, _ = r.ListProducts(ctx, "alice", []string{"tv"})
+	if len(products) != 1 || products[0].ProductID != "tv" {
+		t.Errorf("ListProducts(alice, tv) = %+v", products)
+	}
+	if products, _ := r.ListProducts(ctx, "", nil); len(products) != 0 {
+		t.Errorf("ListProducts(\"\") = %+v; want no products", products)
+	}
+
+	channels, _ := r.ListChannels(ctx, "alice")
+	if len(channels) != 1 || channels[0].ID != "c1" {
+		t.Errorf("ListChannels(alice) = %+v; want only the enabled channel", channels)
+	}
+
+	now := time.Now().UTC()
+	match := domain.Match{SessionID: "s1", ProductID: "ssd", SourceID: -1001, MessageID: 10,
+		PostedAt: now.Format(time.RFC3339), Status: domain.MatchActive}
+	old := match
+	old.MessageID, old.PostedAt = 9, now.Add(-48*time.Hour).Format(time.RFC3339)
+	for _, m := range []domain.Match{match, old} {
+		if err := r.SaveMatch(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	since := now.Add(-24 * time.Hour).Format(time.RFC3339)
+	tracked, _ := r.ListTrackedMatches(ctx, since)
+	if len(tracked) != 1 || tracked[0].MessageID != 10 {
+		t.Errorf("ListTrackedMatches = %+v; want message 10", tracked)
+	}
+	match.Status = domain.MatchDeleted
+	if err := r.UpdateMatch(ctx, match); err != nil {
+		t.Fatal(err)
+	}
+	if tracked, _ := r.ListTrackedMatches(ctx, since); len(tracked) != 0 {
+		t.Errorf("deleted match is still tracked: %+v", tracked)
+	}
+
+	for _, price := range []float64{300, 250} {
+		err := r.RecordPrice(ctx, domain.PricePoint{ProductID: "ssd", OfferID: "amazon:B1", Store: "amazon",
+			Price: price, RecordedAt: now.Format(time.RFC3339)})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	stats, _ := r.PriceStats(ctx, domain.PricePoint{OfferID: "amazon:B1"})
+	if stats.Count != 2 || stats.Lowest != 250 || stats.Count30 != 2 || stats.Average30 != 275 {
+		t.Errorf("PriceStats = %+v", stats)
+	}
+	// Sem offer_id, o histórico é o do produto na loja.
+	stats, _ = r.PriceStats(ctx, domain.PricePoint{ProductID: "ssd", Store: "kabum"})
+	if stats.Count != 0 {
+		t.Errorf("PriceStats of another store = %+v", stats)
+	}
+
+	if _, err := r.GetState(ctx, "cursor"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("GetState of unset key: %v, want ErrNotFound", err)
+	}
+	if err := r.SetState(ctx, "cursor", []byte("42")); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := r.GetState(ctx, "cursor"); err != nil || string(value) != "42" {
+		t.Errorf("GetState = %q, %v", value, err)
+	}
+}
+
+func newSeeded() *Repository {
+	r := &Repository{}
+	r.AddSession(domain.Session{SessionId: "s1", OwnerID: "alice", ProductIds: []string{"ssd", "tv"}})
+	r.AddProduct(domain.Product{ProductID: "ssd", OwnerID: "alice", Name: "ssd"})
+	r.AddProduct(domain.Product{ProductID: "tv", OwnerID: "alice", Name: "tv"})
+	r.AddProduct(domain.Product{ProductID: "phone", OwnerID: "bob", Name: "phone"})
+	r.AddChannel(domain.NotificationChannel{ID: "c1", OwnerID: "alice", Kind: "webhook", Enabled: true})
+	r.AddChannel(domain.NotificationChannel{ID: "c2", OwnerID: "alice", Kind: "telegram"})
+	return r
+}
@@ -0,0 +1,158 @@
+// Package notify entrega os matches nos canais de notificação de cada dono.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"bot-telegram/src/internal/domain"
+
+	"github.com/go-faster/errors"
+)
+
+// Sender delivers a match to one channel.
+type Sender interface {
+	Send(ctx context.Context, channel domain.NotificationChannel, match domain.Match) error
+}
+
+type SenderFunc func(ctx context.Context, channel domain.NotificationChannel, match domain.Match) error
+
+func (f SenderFunc) Send(ctx context.Context, channel domain.NotificationChannel, match domain.Match) error {
+	return f(ctx, channel, match)
+}
+
+// Router sends each match to the channels of its owner.
+type Router struct {
+	Channels domain.NotificationRepository
+	// Senders by channel kind (domain.ChannelWebhook, domain.ChannelTelegram).
+	Senders map[string]Sender
+	// Fallback receives the matches of owners without channels; optional.
+	Fallback Sender
+	// CacheTTL is how long the channels of an owner are reused. Defaults to
+	// one minute.
+	CacheTTL time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedChannels
+}
+
+type cachedChannels struct {
+	channels []domain.NotificationChannel
+	at       time.Time
+}
+
+func (r *Router) Notify(ctx context.Context, match domain.Match) error {
+	channels, err := r.channels(ctx, match.OwnerID)
+	if err != nil {
+		return err
+	}
+
+	if len(channels) == 0 {
+		if r.Fallback == nil {
+			return nil
+		}
+		return r.Fallback.Send(ctx, domain.NotificationChannel{OwnerID: match.OwnerID}, match)
+	}
+
+	var errs []error
+	for _, channel := range channels {
+		sender, ok := r.Senders[channel.Kind]
+		if !ok {
+			errs = append(errs, errors.Errorf("[NOTIFY] channel %s: unknown kind %q", channel.ID, channel.Kind))
+			continue
+		}
+		if err := sender.Send(ctx, channel, match); err != nil {
+			errs = append(errs, errors.Wrapf(err, "[NOTIFY] channel %s", channel.ID))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r *Router) channels(ctx context.Context, owner string) ([]domain.NotificationChannel, error) {
+	if owner == "" {
+		return nil, nil
+	}
+
+	ttl := r.CacheTTL
+	if ttl <= 0 {
+		ttl = time.Minute
+	}
+
+	r.mu.Lock()
+	cached, ok := r.cache[owner]
+	r.mu.Unlock()
+	if ok && time.Since(cached.at) < ttl {
+		return cached.channels, nil
+	}
+
+	channels, err := r.Channels.ListChannels(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	if r.cache == nil {
+		r.cache = make(map[string]cachedChannels)
+	}
+	r.cache[owner] = cachedChannels{channels: channels, at: time.Now()}
+	r.mu.Unlock()
+
+	return channels, nil
+}
+
+// Format is the text of a match in notifications.
+func Format(match domain.Match) string {
+	var b strings.Builder
+
+	title := match.ProductName
+	if title == "" {
+		title = "Cupom"
+	}
+	switch match.Status {
+	case domain.MatchSoldOut:
+		fmt.Fprintln(&b, "⛔ ESGOTADO")
+	case domain.MatchExpired:
+		fmt.Fprintln(&b, "⛔ Oferta encerrada")
+	case domain.MatchDeleted:
+		fmt.Fprintln(&b, "🗑  Post apagado")
+	case domain.MatchPriceChanged:
+		fmt.Fprintln(&b, "✏️  Preço alterado")
+	}
+	fmt.Fprintf(&b, "🔍 [%s] %s\n", title, match.Text)
+	if match.Price > 0 && match.PreviousPrice > 0 {
+		fmt.Fprintf(&b, "    💰 R$ %.2f (antes R$ %.2f)\n", match.Price, match.PreviousPrice)
+	} else if match.Price > 0 {
+		fmt.Fprintf(&b, "    💰 R$ %.2f\n", match.Price)
+	}
+	if match.HistoricalLow {
+		fmt.Fprintln(&b, "    📉 Menor preço histórico")
+	} else if match.BelowAverage {
+		fmt.Fprintln(&b, "    📉 Abaixo da média dos últimos 30 dias")
+	}
+	for _, c := range match.Coupons {
+		fmt.Fprintf(&b, "    🎟  %s %s %s\n", c.Code, c.Discount, c.Expires)
+	}
+	for _, link := range match.Links {
+		fmt.Fprintf(&b, "    🔗 %s\n", link)
+	}
+	fmt.Fprintf(&b, "    📅 Data: %s\n", match.PostedAt)
+
+	return b.String()
+}
+
+// Writer prints the matches to w, e.g. os.Stdout.
+func Writer(w io.Writer) Sender {
+	var mu sync.Mutex
+	return SenderFunc(func(ctx context.Context, channel domain.NotificationChannel, match domain.Match) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		_, err := fmt.Fprintln(w, Format(match))
+		return err
+	})
+}
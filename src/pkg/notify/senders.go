@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"bot-telegram/src/internal/domain"
+
+	"github.com/go-faster/errors"
+)
+
+// Webhook posts the match as JSON to the channel target.
+type Webhook struct {
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+func (w Webhook) Send(ctx context.Context, channel domain.NotificationChannel, match domain.Match) error {
+	body, err := json.Marshal(match)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, channel.Target, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "webhook request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return do(w.Client, req)
+}
+
+// TelegramBot sends the match with the Bot API to the chat in the channel
+// target. The user must have started a conversation with the bot.
+type TelegramBot struct {
+	Token string
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+	// BaseURL defaults to https://api.telegram.org.
+	BaseURL string
+}
+
+func (t TelegramBot) Send(ctx context.Context, channel domain.NotificationChannel, match domain.Match) error {
+	if t.Token == "" {
+		return errors.New("TELEGRAM_BOT_TOKEN is not set")
+	}
+	base := t.BaseURL
+	if base == "" {
+		base = "https://api.telegram.org"
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"chat_id": channel.Target,
+		"text":    Format(match),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base+"/bot"+t.Token+"/sendMessage", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "telegram bot request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return do(t.Client, req)
+}
+
+func do(client *http.Client, req *http.Request) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// Não inclui a URL: a do bot tem o token.
+		return errors.Errorf("%s request failed: %v", req.Method, errors.Unwrap(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return errors.Errorf("status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	return nil
+}
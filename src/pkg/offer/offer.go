@@ -0,0 +1,102 @@
+// Package offer identifica os posts de ofertas que já acabaram: esgotadas,
+// encerradas ou riscadas pelo canal.
+package offer
+
+import (
+	"regexp"
+	"unicode"
+	"unicode/utf16"
+
+	"bot-telegram/src/internal/domain"
+
+	"github.com/gotd/td/tg"
+)
+
+type Status string
+
+const (
+	Available Status = "available"
+	// SoldOut is a post saying the stock ran out ("esgotado", "acabou o
+	// estoque").
+	SoldOut Status = "sold_out"
+	// Expired is a post saying the promotion ended ("encerrada", "expirou")
+	// or crossed out by the channel.
+	Expired Status = "expired"
+)
+
+// Ended reports whether the offer can no longer be bought.
+func (s Status) Ended() bool {
+	return s == SoldOut || s == Expired
+}
+
+// MatchStatus is the domain.Match status of an offer with status s.
+func (s Status) MatchStatus() string {
+	switch s {
+	case SoldOut:
+		return domain.MatchSoldOut
+	case Expired:
+		return domain.MatchExpired
+	default:
+		return domain.MatchActive
+	}
+}
+
+var (
+	// "Acabou" sozinho não basta: "Acabou a espera!", "acabou de sair".
+	soldOutPattern = regexp.MustCompile(`(?i)\b(?:esgotad[oa]s?|esgotou|esgotaram|sem\s+estoque|sold\s*out|acab(?:ou|aram)\s+(?:(?:o|os|as)\s+)?(?:estoques?|unidades)|estoques?\s+(?:acab(?:ou|aram)|zerad[oa]s?))\b`)
+	expiredPattern = regexp.MustCompile(`(?i)\b(?:encerrad[oa]s?|encerr(?:ou|aram)|expirad[oa]s?|expirou|finalizad[oa]s?|fim\s+d[ao]\s+(?:promo|oferta)|(?:cupom|cupons|promo\S*|oferta)\s+(?:inv[aá]lid[oa]|vencid[oa]|off))`)
+)
+
+// strikeCoverage is the share of the text that has to be crossed out for the
+// post to count as expired; a struck old price ("de ~R$ 200~ por R$ 150") is
+// not enough.
+const strikeCoverage = 0.5
+
+// Classify returns the status of the offer in message, from its text and
+// strikethrough entities. Edited posts are classified the same way.
+func Classify(message *tg.Message) Status {
+	text := message.Message
+
+	if soldOutPattern.MatchString(text) {
+		return SoldOut
+	}
+	if expiredPattern.MatchString(text) {
+		return Expired
+	}
+	if struck(message) >= strikeCoverage {
+		return Expired
+	}
+	return Available
+}
+
+// struck returns the share of the visible characters of message that are
+// inside strikethrough entities.
+func struck(message *tg.Message) float64 {
+	units := utf16.Encode([]rune(message.Message))
+
+	crossed := make([]bool, len(units))
+	for _, entity := range message.Entities {
+		strike, ok := entity.(*tg.MessageEntityStrike)
+		if !ok || strike.Offset < 0 || strike.Length <= 0 || strike.Offset+strike.Length > len(units) {
+			continue
+		}
+		for i := strike.Offset; i < strike.Offset+strike.Length; i++ {
+			crossed[i] = true
+		}
+	}
+
+	var visible, hidden int
+	for i, unit := range units {
+		if unit < 0x80 && unicode.IsSpace(rune(unit)) {
+			continue
+		}
+		visible++
+		if crossed[i] {
+			hidden++
+		}
+	}
+	if visible == 0 {
+		return 0
+	}
+	return float64(hidden) / float64(visible)
+}
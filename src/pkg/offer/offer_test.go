@@ -0,0 +1,46 @@
+package offer
+
+import (
+	"testing"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		text     string
+		entities []tg.MessageEntityClass
+		want     Status
+	}{
+		{text: "SSD 1TB por R$ 299", want: Available},
+		{text: "Acabou a espera! SSD 1TB por R$ 299", want: Available},
+		{text: "Acabou de sair: SSD 1TB por R$ 299", want: Available},
+		{text: "Acabaram de baixar o preço do SSD", want: Available},
+		{text: "ESGOTADO\nSSD 1TB por R$ 299", want: SoldOut},
+		{text: "Esgotou em 5 minutos", want: SoldOut},
+		{text: "Acabou o estoque do SSD", want: SoldOut},
+		{text: "acabaram as unidades", want: SoldOut},
+		{text: "Estoque zerado", want: SoldOut},
+		{text: "Produto sem estoque", want: SoldOut},
+		{text: "Promoção encerrada", want: Expired},
+		{text: "O cupom expirou", want: Expired},
+		{text: "Cupom inválido", want: Expired},
+		{
+			text:     "de R$ 399 por R$ 299",
+			entities: []tg.MessageEntityClass{&tg.MessageEntityStrike{Offset: 3, Length: 6}},
+			want:     Available,
+		},
+		{
+			text:     "SSD 1TB por R$ 299",
+			entities: []tg.MessageEntityClass{&tg.MessageEntityStrike{Offset: 0, Length: 18}},
+			want:     Expired,
+		},
+	}
+
+	for _, tt := range tests {
+		got := Classify(&tg.Message{Message: tt.text, Entities: tt.entities})
+		if got != tt.want {
+			t.Errorf("Classify(%q) = %s, want %s", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"bot-telegram/src/internal/domain"
+	"bot-telegram/src/pkg/telegram"
+
+	"github.com/gotd/td/tg"
+)
+
+// CursorKey é a chave, no estado, do ID da última mensagem da fonte lida pela
+// sessão. Em grupos comuns os IDs das mensagens são de cada conta, então a
+// chave inclui a conta que os leu: se outra conta assumir, começa um cursor novo.
+func CursorKey(sessionID string, sourceID int64, account string) string {
+	if telegram.PerAccountMessageIDs(sourceID) {
+		return fmt.Sprintf("cursor:%s:%d:%s", sessionID, sourceID, account)
+	}
+	return fmt.Sprintf("cursor:%s:%d", sessionID, sourceID)
+}
+
+// cursorSet junta o maior ID de mensagem buscado por cursor numa execução.
+// Cursores com uma mensagem que não pôde ser gravada ficam retidos, para a
+// próxima execução buscá-la de novo.
+type cursorSet struct {
+	mu   sync.Mutex
+	read map[string]int
+	held map[string]bool
+}
+
+func newCursorSet() *cursorSet {
+	return &cursorSet{read: make(map[string]int), held: make(map[string]bool)}
+}
+
+func (c *cursorSet) advance(key string, id int) {
+	c.mu.Lock()
+	if id > c.read[key] {
+		c.read[key] = id
+	}
+	c.mu.Unlock()
+}
+
+func (c *cursorSet) hold(key string) {
+	c.mu.Lock()
+	c.held[key] = true
+	c.mu.Unlock()
+}
+
+// fetch retorna as mensagens da fonte mais novas que o cursor.
+func (p *Pipeline) fetch(ctx context.Context, key string, source telegram.Source) ([]*tg.Message, error) {
+	minID, err := p.loadCursor(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := p.cfg.Messages.FetchMessages(ctx, source, minID)
+	if err != nil {
+		return nil, err
+	}
+
+	// O fetcher pode ignorar minID; filtra de novo.
+	fresh := messages[:0]
+	for _, message := range messages {
+		if message.ID > minID {
+			fresh = append(fresh, message)
+		}
+	}
+	return fresh, nil
+}
+
+func (p *Pipeline) loadCursor(ctx context.Context, key string) (int, error) {
+	if p.cfg.Cursors == nil {
+		return 0, nil
+	}
+
+	value, err := p.cfg.Cursors.GetState(ctx, key)
+	if errors.Is(err, domain.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("load cursor %s: %w", key, err)
+	}
+
+	id, err := strconv.Atoi(string(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor %s: %q", key, value)
+	}
+	return id, nil
+}
+
+func (p *Pipeline) saveCursors(ctx context.Context, cursors *cursorSet) error {
+	if p.cfg.Cursors == nil {
+		return nil
+	}
+
+	var errs []error
+	for key, id := range cursors.read {
+		if cursors.held[key] {
+			continue
+		}
+		if err := p.cfg.Cursors.SetState(ctx, key, []byte(strconv.Itoa(id))); err != nil {
+			errs = append(errs, fmt.Errorf("save cursor %s: %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,104 @@
+package pipeline
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"bot-telegram/src/internal/domain"
+
+	"github.com/gotd/td/tg"
+)
+
+// RegexMatcher trata o nome do produto como uma expressão regular, sem
+// diferenciar maiúsculas de minúsculas.
+type RegexMatcher struct {
+	mu    sync.Mutex
+	cache map[string]*regexp.Regexp
+}
+
+func NewRegexMatcher() *RegexMatcher {
+	return &RegexMatcher{cache: make(map[string]*regexp.Regexp)}
+}
+
+func (m *RegexMatcher) Match(product domain.Product, message *tg.Message) (bool, error) {
+	m.mu.Lock()
+	re, ok := m.cache[product.Name]
+	if !ok {
+		var err error
+		re, err = regexp.Compile("(?i)" + product.Name)
+		if err != nil {
+			m.mu.Unlock()
+			return false, fmt.Errorf("regex inválida para o produto %s: %w", product.ProductID, err)
+		}
+		m.cache[product.Name] = re
+	}
+	m.mu.Unlock()
+
+	return re.MatchString(message.Message), nil
+}
+
+// Reset descarta as expressões compiladas, por exemplo quando os produtos mudam.
+func (m *RegexMatcher) Reset() {
+	m.mu.Lock()
+	m.cache = make(map[string]*regexp.Regexp)
+	m.mu.Unlock()
+}
+
+// MemoryDeduper lembra os matches em memória por ttl.
+type MemoryDeduper struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	seen      map[string]time.Time
+	lastPrune time.Time
+}
+
+func NewMemoryDeduper(ttl time.Duration) *MemoryDeduper {
+	return &MemoryDeduper{ttl: ttl, seen: make(map[string]time.Time), lastPrune: time.Now()}
+}
+
+func (d *MemoryDeduper) Seen(match domain.Match) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(d.lastPrune) > d.ttl {
+		for key, at := range d.seen {
+			if now.Sub(at) > d.ttl {
+				delete(d.seen, key)
+			}
+		}
+		d.lastPrune = now
+	}
+
+	key := matchKey(match)
+	if at, ok := d.seen[key]; ok && now.Sub(at) <= d.ttl {
+		return true
+	}
+	d.seen[key] = now
+	return false
+}
+
+func (d *MemoryDeduper) Forget(match domain.Match) {
+	d.mu.Lock()
+	delete(d.seen, matchKey(match))
+	d.mu.Unlock()
+}
+
+// matchKey é a oferta, quando conhecida, ou os códigos dos cupons nos matches
+// de cupom, para a mesma promoção postada em vários canais ser avisada uma vez.
+func matchKey(match domain.Match) string {
+	if match.ProductID == "" && len(match.Coupons) > 0 {
+		codes := make([]string, len(match.Coupons))
+		for i, c := range match.Coupons {
+			codes[i] = strings.ToUpper(c.Code)
+		}
+		return fmt.Sprintf("%s/coupon/%s/%s", match.SessionID, match.Store, strings.Join(codes, ","))
+	}
+	if match.OfferID != "" {
+		return fmt.Sprintf("%s/%s/%s", match.SessionID, match.ProductID, match.OfferID)
+	}
+	return fmt.Sprintf("%s/%s/%d/%d", match.SessionID, match.ProductID, match.SourceID, match.MessageID)
+}
@@ -0,0 +1,483 @@
+// Package pipeline busca os produtos de cada sessão nos canais do Telegram.
+//
+// Cada execução passa pelos estágios load sessions → resolve sources →
+// fetch messages → match → resolve links → dedupe → price history → persist →
+// notify. Os estágios são ligados por canais com buffer, então um estágio
+// lento segura os anteriores em vez de acumular itens em memória.
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"bot-telegram/src/internal/domain"
+	"bot-telegram/src/pkg/coupon"
+	"bot-telegram/src/pkg/links"
+	"bot-telegram/src/pkg/offer"
+	"bot-telegram/src/pkg/price"
+	"bot-telegram/src/pkg/retailer"
+
+	"golang.org/x/sync/errgroup"
+)
+
+type Config struct {
+	Sessions SessionLoader
+	Sources  SourceResolver
+	Messages MessageFetcher
+
+	// Matcher padrão: NewRegexMatcher.
+	Matcher Matcher
+	// Deduper padrão: NewMemoryDeduper(24 * time.Hour).
+	Deduper Deduper
+	// Links expande os links de cada match; opcional, sem ele os links ficam
+	// como aparecem na mensagem.
+	Links LinkResolver
+	// Retailers limpa os links e identifica a loja e o produto de cada match.
+	// Padrão: retailer.Default.
+	Retailers *retailer.Registry
+	// Prices grava o preço de cada match e marca os menores preços
+	// históricos; opcional.
+	Prices domain.PriceRepository
+	// Cursors guarda a última mensagem lida de cada canal por sessão, para a
+	// próxima execução buscar só as mais novas; opcional.
+	Cursors domain.StateRepository
+	// Store e Notifier são opcionais.
+	Store    Store
+	Notifier Notifier
+	// NotifyEnded também notifica os posts de ofertas esgotadas ou
+	// encerradas (veja offer.Classify), marcados com o status. Sem ele esses
+	// matches são gravados, mas não notificados.
+	NotifyEnded bool
+
+	// Buffer é a capacidade dos canais entre os estágios.
+	Buffer int
+	// FetchWorkers é quantos canais são buscados em paralelo.
+	FetchWorkers int
+
+	// OnError é chamado quando um item falha num estágio. O item é
+	// descartado; retornar um erro aborta a execução. Padrão: loga o erro.
+	// Falha ao carregar as sessões sempre aborta a execução.
+	OnError func(err *StageError) error
+}
+
+type Pipeline struct {
+	cfg Config
+}
+
+func New(cfg Config) (*Pipeline, error) {
+	if cfg.Sessions == nil || cfg.Sources == nil || cfg.Messages == nil {
+		return nil, errors.New("[PIPELINE] sessions, sources and messages stages are required")
+	}
+	if cfg.Matcher == nil {
+		cfg.Matcher = NewRegexMatcher()
+	}
+	if cfg.Deduper == nil {
+		cfg.Deduper = NewMemoryDeduper(24 * time.Hour)
+	}
+	if cfg.Retailers == nil {
+		cfg.Retailers = retailer.Default
+	}
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = 16
+	}
+	if cfg.FetchWorkers <= 0 {
+		cfg.FetchWorkers = 1
+	}
+	if cfg.OnError == nil {
+		cfg.OnError = func(err *StageError) error {
+			log.Printf("[PIPELINE] %v", err)
+			return nil
+		}
+	}
+
+	return &Pipeline{cfg: cfg}, nil
+}
+
+type sessionsKey struct{}
+
+// OnlySessions restringe as execuções com o contexto retornado às sessões
+// dadas, por exemplo as que venceram no agendamento.
+func OnlySessions(ctx context.Context, ids ...string) context.Context {
+	only := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		only[id] = true
+	}
+	return context.WithValue(ctx, sessionsKey{}, only)
+}
+
+// Run executa uma passada por todas as sessões e retorna quando todos os
+// estágios terminam. Os cursores de leitura só avançam se a execução inteira
+// der certo e, por fonte, se todos os matches dela foram gravados.
+func (p *Pipeline) Run(ctx context.Context) error {
+	cursors := newCursorSet()
+	if err := p.run(ctx, cursors); err != nil {
+		return err
+	}
+	return p.saveCursors(context.WithoutCancel(ctx), cursors)
+}
+
+func (p *Pipeline) run(ctx context.Context, cursors *cursorSet) error {
+	g, ctx := errgroup.WithContext(ctx)
+
+	jobs := make(chan Job, p.cfg.Buffer)
+	sources := make(chan sourceJob, p.cfg.Buffer)
+	messages := make(chan messageJob, p.cfg.Buffer)
+	matches := make(chan matchJob, p.cfg.Buffer)
+	resolved := make(chan domain.Match, p.cfg.Buffer)
+	unique := make(chan domain.Match, p.cfg.Buffer)
+	priced := make(chan domain.Match, p.cfg.Buffer)
+	persisted := make(chan domain.Match, p.cfg.Buffer)
+
+	g.Go(func() error {
+		defer close(jobs)
+		return p.loadSessions(ctx, jobs)
+	})
+	g.Go(func() error {
+		defer close(sources)
+		return p.resolveSources(ctx, jobs, sources)
+	})
+	g.Go(func() error {
+		defer close(messages)
+		workers, ctx := errgroup.WithContext(ctx)
+		for i := 0; i < p.cfg.FetchWorkers; i++ {
+			workers.Go(func() error { return p.fetchMessages(ctx, sources, messages, cursors) })
+		}
+		return workers.Wait()
+	})
+	g.Go(func() error {
+		defer close(matches)
+		return p.match(ctx, messages, matches)
+	})
+	g.Go(func() error {
+		defer close(resolved)
+		return p.resolveLinks(ctx, matches, resolved)
+	})
+	g.Go(func() error {
+		defer close(unique)
+		return p.dedupe(ctx, resolved, unique)
+	})
+	g.Go(func() error {
+		defer close(priced)
+		return p.priceHistory(ctx, unique, priced)
+	})
+	g.Go(func() error {
+		defer close(persisted)
+		return p.persist(ctx, priced, persisted, cursors)
+	})
+	g.Go(func() error {
+		return p.notify(ctx, persisted)
+	})
+
+	return g.Wait()
+}
+
+func (p *Pipeline) loadSessions(ctx context.Context, out chan<- Job) error {
+	jobs, err := p.cfg.Sessions.LoadSessions(ctx)
+	if err != nil {
+		return &StageError{Stage: StageLoadSessions, Err: err}
+	}
+
+	only, filtered := ctx.Value(sessionsKey{}).(map[string]bool)
+	for _, job := range jobs {
+		if filtered && !only[job.Session.SessionId] {
+			continue
+		}
+		if err := send(ctx, out, job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Pipeline) resolveSources(ctx context.Context, in <-chan Job, out chan<- sourceJob) error {
+	for job := range in {
+		if len(job.Products) == 0 && !job.Session.WatchCoupons {
+			continue
+		}
+
+		sources, err := p.cfg.Sources.ResolveSources(ctx, job.Session)
+		if err != nil {
+			if err := p.fail(&StageError{Stage: StageResolveSources, SessionID: job.Session.SessionId, Err: err}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, source := range sources {
+			if err := send(ctx, out, sourceJob{Job: job, Source: source}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *Pipeline) fetchMessages(ctx context.Context, in <-chan sourceJob, out chan<- messageJob, cursors *cursorSet) error {
+	for job := range in {
+		key := CursorKey(job.Session.SessionId, job.Source.ID, job.Source.Account)
+		messages, err := p.fetch(ctx, key, job.Source)
+		if err != nil {
+			if err := p.fail(&StageError{
+				Stage:     StageFetchMessages,
+				SessionID: job.Session.SessionId,
+				SourceID:  job.Source.ID,
+				Err:       err,
+			}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, message := range messages {
+			cursors.advance(key, message.ID)
+			if err := send(ctx, out, messageJob{sourceJob: job, Message: message}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *Pipeline) match(ctx context.Context, in <-chan messageJob, out chan<- matchJob) error {
+	for job := range in {
+		coupons := coupon.Extract(job.Message)
+		status := offer.Classify(job.Message)
+
+		for _, product := range job.Products {
+			ok, err := p.cfg.Matcher.Match(product, job.Message)
+			if err != nil {
+				if err := p.fail(&StageError{
+					Stage:     StageMatch,
+					SessionID: job.Session.SessionId,
+					SourceID:  job.Source.ID,
+					Err:       err,
+				}); err != nil {
+					return err
+				}
+				continue
+			}
+			if !ok {
+				continue
+			}
+
+			if err := send(ctx, out, newMatchJob(job, product, coupons, status)); err != nil {
+				return err
+			}
+		}
+
+		// Sessões de cupons recebem o cupom mesmo sem produto.
+		if job.Session.WatchCoupons && len(coupons) > 0 {
+			if err := send(ctx, out, newMatchJob(job, domain.Product{}, coupons, status)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func newMatchJob(job messageJob, product domain.Product, coupons []domain.Coupon, status offer.Status) matchJob {
+	amount, _ := price.Extract(job.Message.Message)
+	return matchJob{
+		Match: domain.Match{
+			SessionID:   job.Session.SessionId,
+			OwnerID:     job.Session.OwnerID,
+			ProductID:   product.ProductID,
+			ProductName: product.Name,
+			SourceID:    job.Source.ID,
+			Account:     job.Source.Account,
+			MessageID:   job.Message.ID,
+			Text:        job.Message.Message,
+			Links:       links.Extract(job.Message),
+			Coupons:     coupons,
+			Price:       amount,
+			PostedAt:    time.Unix(int64(job.Message.Date), 0).UTC().Format(time.RFC3339),
+			Status:      status.MatchStatus(),
+		},
+		stores: job.Session.Stores,
+	}
+}
+
+func (p *Pipeline) dedupe(ctx context.Context, in <-chan domain.Match, out chan<- domain.Match) error {
+	for match := range in {
+		// Post de oferta encerrada não marca a oferta como vista: ela ainda
+		// pode voltar ativa em outro canal.
+		if !ended(match) && p.cfg.Deduper.Seen(match) {
+			continue
+		}
+		if err := send(ctx, out, match); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveLinks troca cada link pelo destino dele, sem rastreio, e identifica a
+// loja e o produto da oferta. Um link que falha fica como está, depois de
+// reportado. Matches de lojas que a sessão não acompanha são descartados.
+func (p *Pipeline) resolveLinks(ctx context.Context, in <-chan matchJob, out chan<- domain.Match) error {
+	for job := range in {
+		match := job.Match
+		for i, link := range match.Links {
+			if p.cfg.Links != nil {
+				resolved, err := p.cfg.Links.Resolve(ctx, link)
+				if err != nil {
+					if err := p.fail(matchError(StageResolveLinks, match, err)); err != nil {
+						return err
+					}
+				} else {
+					link = resolved
+				}
+			}
+
+			listing, err := p.cfg.Retailers.Parse(link)
+			if err != nil {
+				continue
+			}
+			match.Links[i] = listing.URL
+			if match.OfferID == "" && listing.Key() != "" {
+				match.Store, match.OfferID = listing.Store, listing.Key()
+			}
+			if match.Store == "" {
+				match.Store = listing.Store
+			}
+		}
+
+		if match.Store == "" {
+			match.Store = p.cfg.Retailers.Mention(match.Text)
+		}
+
+		if len(job.stores) > 0 && !slices.Contains(job.stores, match.Store) {
+			continue
+		}
+		if err := send(ctx, out, match); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// priceHistory compara o preço com o histórico da oferta antes de gravá-lo.
+// Matches sem preço ou sem produto (só cupom) passam direto. O post que casa
+// com várias sessões grava um ponto só.
+func (p *Pipeline) priceHistory(ctx context.Context, in <-chan domain.Match, out chan<- domain.Match) error {
+	recorded := make(map[string]domain.PriceStats)
+	for match := range in {
+		if p.cfg.Prices != nil && match.Price > 0 && match.ProductID != "" {
+			point := domain.PricePoint{
+				ProductID:  match.ProductID,
+				OfferID:    match.OfferID,
+				Store:      match.Store,
+				Price:      match.Price,
+				SourceID:   match.SourceID,
+				MessageID:  match.MessageID,
+				RecordedAt: match.PostedAt,
+			}
+
+			stats, err := p.recordPrice(ctx, point, recorded)
+			if err == nil {
+				match.HistoricalLow = stats.Count > 0 && match.Price < stats.Lowest
+				match.BelowAverage = stats.Count30 > 0 && match.Price < stats.Average30
+			}
+			if err != nil {
+				if err := p.fail(matchError(StagePriceHistory, match, err)); err != nil {
+					return err
+				}
+			}
+		}
+		if err := send(ctx, out, match); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordPrice grava point e retorna o histórico anterior a ele; se o post já
+// foi gravado nesta execução, só retorna o histórico guardado em recorded.
+func (p *Pipeline) recordPrice(ctx context.Context, point domain.PricePoint, recorded map[string]domain.PriceStats) (domain.PriceStats, error) {
+	key := pointKey(point)
+	if stats, ok := recorded[key]; ok {
+		return stats, nil
+	}
+
+	stats, err := p.cfg.Prices.PriceStats(ctx, point)
+	if err != nil {
+		return stats, err
+	}
+	if err := p.cfg.Prices.RecordPrice(ctx, point); err != nil {
+		return stats, err
+	}
+	recorded[key] = stats
+	return stats, nil
+}
+
+// pointKey identifica o post e a oferta como o PriceStats: pelo offer_id ou,
+// sem ele, pelo produto e a loja.
+func pointKey(point domain.PricePoint) string {
+	if point.OfferID != "" {
+		return fmt.Sprintf("%d/%d/%s", point.SourceID, point.MessageID, point.OfferID)
+	}
+	return fmt.Sprintf("%d/%d/%s/%s", point.SourceID, point.MessageID, point.ProductID, point.Store)
+}
+
+// persist grava os matches. Um match não gravado é esquecido pelo Deduper e
+// o cursor da fonte fica onde estava, para o post ser lido de novo na próxima
+// execução. É o único erro que perde o post e que ler de novo resolve (nos
+// outros estágios o match segue, no notify ele já foi gravado e uma regex
+// inválida falharia de novo).
+func (p *Pipeline) persist(ctx context.Context, in <-chan domain.Match, out chan<- domain.Match, cursors *cursorSet) error {
+	for match := range in {
+		if p.cfg.Store != nil {
+			if err := p.cfg.Store.SaveMatch(ctx, match); err != nil {
+				p.cfg.Deduper.Forget(match)
+				cursors.hold(CursorKey(match.SessionID, match.SourceID, match.Account))
+				if err := p.fail(matchError(StagePersist, match, err)); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if err := send(ctx, out, match); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Pipeline) notify(ctx context.Context, in <-chan domain.Match) error {
+	for match := range in {
+		if p.cfg.Notifier == nil || (ended(match) && !p.cfg.NotifyEnded) {
+			continue
+		}
+		if err := p.cfg.Notifier.Notify(ctx, match); err != nil {
+			if err := p.fail(matchError(StageNotify, match, err)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func ended(match domain.Match) bool {
+	return match.Status == domain.MatchSoldOut || match.Status == domain.MatchExpired
+}
+
+func (p *Pipeline) fail(err *StageError) error {
+	return p.cfg.OnError(err)
+}
+
+func matchError(stage string, match domain.Match, err error) *StageError {
+	return &StageError{Stage: stage, SessionID: match.SessionID, SourceID: match.SourceID, Err: err}
+}
+
+func send[T any](ctx context.Context, out chan<- T, v T) error {
+	select {
+	case out <- v:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
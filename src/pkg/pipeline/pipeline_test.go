@@ -0,0 +1,243 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"bot-telegram/src/internal/domain"
+	"bot-telegram/src/pkg/memory"
+	"bot-telegram/src/pkg/telegram"
+
+	"github.com/gotd/td/tg"
+)
+
+var testSource = telegram.Source{ID: -1000000000001, Kind: telegram.SourceBroadcast, Peer: &tg.InputPeerChannel{ChannelID: 1}}
+
+// testChannel é um canal com as mensagens dadas; guarda o minID de cada busca.
+type testChannel struct {
+	mu       sync.Mutex
+	messages []*tg.Message
+	minIDs   []int
+}
+
+func (c *testChannel) post(id int, text string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = append(c.messages, &tg.Message{ID: id, Message: text, Date: int(time.Now().Unix())})
+}
+
+func (c *testChannel) FetchMessages(ctx context.Context, source telegram.Source, minID int) ([]*tg.Message, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.minIDs = append(c.minIDs, minID)
+
+	var messages []*tg.Message
+	for _, m := range c.messages {
+		if m.ID > minID {
+			messages = append(messages, m)
+		}
+	}
+	return messages, nil
+}
+
+func newTestPipeline(t *testing.T, repo *memory.Repository, channel *testChannel, store Store, notified *[]domain.Match) *Pipeline {
+	var mu sync.Mutex
+	p, err := New(Config{
+		Sessions: SessionLoaderFunc(func(ctx context.Context) ([]Job, error) {
+			sessions, err := repo.ListSessions(ctx)
+			if err != nil {
+				return nil, err
+			}
+			var jobs []Job
+			for _, session := range sessions {
+				products, err := repo.ListProducts(ctx, session.OwnerID, session.ProductIds)
+				if err != nil {
+					return nil, err
+				}
+				jobs = append(jobs, Job{Session: session, Products: products})
+			}
+			return jobs, nil
+		}),
+		Sources: SourceResolverFunc(func(ctx context.Context, session domain.Session) ([]telegram.Source, error) {
+			return []telegram.Source{testSource}, nil
+		}),
+		Messages: channel,
+		Prices:   repo,
+		Cursors:  repo,
+		Store:    store,
+		Notifier: NotifierFunc(func(ctx context.Context, match domain.Match) error {
+			mu.Lock()
+			defer mu.Unlock()
+			*notified = append(*notified, match)
+			return nil
+		}),
+		FetchWorkers: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func newTestRepository() *memory.Repository {
+	repo := &memory.Repository{}
+	repo.AddProduct(domain.Product{ProductID: "ssd-alice", OwnerID: "alice", Name: `ssd\s*1\s*tb`})
+	repo.AddProduct(domain.Product{ProductID: "ssd-bob", OwnerID: "bob", Name: `ssd`})
+	repo.AddSession(domain.Session{SessionId: "s1", OwnerID: "alice", ProductIds: []string{"ssd-alice"}})
+	repo.AddSession(domain.Session{SessionId: "s2", OwnerID: "bob", ProductIds: []string{"ssd-bob"}})
+	return repo
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository()
+	channel := &testChannel{}
+	channel.post(10, "SSD 1TB por R$ 299\nhttps://www.amazon.com.br/SSD/dp/B09B8VGCR8/ref=x?tag=promo-20")
+	channel.post(11, "TV 50\" por R$ 1.999")
+	channel.post(12, "SSD 1TB ESGOTADO https://www.amazon.com.br/dp/B000000001")
+
+	var notified []domain.Match
+	p := newTestPipeline(t, repo, channel, repo, &notified)
+
+	if err := p.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	matches := repo.Matches()
+	if len(matches) != 4 {
+		t.Fatalf("saved %d matches, want 4: %+v", len(matches), matches)
+	}
+	for _, m := range matches {
+		switch m.MessageID {
+		case 10:
+			if m.OfferID != "amazon:B09B8VGCR8" || m.Price != 299 || m.Status != domain.MatchActive ||
+				!slices.Equal(m.Links, []string{"https://www.amazon.com.br/dp/B09B8VGCR8"}) {
+				t.Errorf("match of message 10 = %+v", m)
+			}
+		case 12:
+			if m.Status != domain.MatchSoldOut {
+				t.Errorf("match of message 12 has status %s, want %s", m.Status, domain.MatchSoldOut)
+			}
+		default:
+			t.Errorf("unexpected match of message %d", m.MessageID)
+		}
+	}
+
+	// Esgotado é gravado, mas não notificado.
+	if len(notified) != 2 {
+		t.Errorf("notified %d matches, want 2", len(notified))
+	}
+
+	// Duas sessões com o mesmo post: um ponto só no histórico.
+	stats, err := repo.PriceStats(ctx, domain.PricePoint{OfferID: "amazon:B09B8VGCR8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Count != 1 {
+		t.Errorf("recorded %d prices for the offer, want 1", stats.Count)
+	}
+
+	for _, session := range []string{"s1", "s2"} {
+		value, err := repo.GetState(ctx, CursorKey(session, testSource.ID, ""))
+		if err != nil || string(value) != "12" {
+			t.Errorf("cursor of %s = %q, %v; want 12", session, value, err)
+		}
+	}
+
+	// A próxima execução só busca as mensagens novas.
+	channel.minIDs = nil
+	channel.post(13, "SSD 1TB por R$ 289 https://www.amazon.com.br/dp/B0SSD00002")
+	if err := p.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(channel.minIDs, []int{12, 12}) {
+		t.Errorf("second run fetched after %v, want [12 12]", channel.minIDs)
+	}
+	if len(repo.Matches()) != 6 {
+		t.Errorf("second run saved %d matches, want 2", len(repo.Matches())-4)
+	}
+}
+
+func TestRunHoldsCursorOfUnsavedMatches(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository()
+	channel := &testChannel{}
+	channel.post(10, "SSD 1TB por R$ 299")
+
+	var notified []domain.Match
+	store := StoreFunc(func(ctx context.Context, match domain.Match) error {
+		if match.SessionID == "s1" {
+			return errors.New("database is down")
+		}
+		return repo.SaveMatch(ctx, match)
+	})
+	p := newTestPipeline(t, repo, channel, store, &notified)
+
+	if err := p.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.GetState(ctx, CursorKey("s1", testSource.ID, "")); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("cursor of s1 was saved: %v", err)
+	}
+	if value, err := repo.GetState(ctx, CursorKey("s2", testSource.ID, "")); err != nil || string(value) != "10" {
+		t.Errorf("cursor of s2 = %q, %v; want 10", value, err)
+	}
+}
+
+func TestRunRereadsUnsavedMatches(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository()
+	channel := &testChannel{}
+	channel.post(10, "SSD 1TB por R$ 299 https://www.amazon.com.br/dp/B09B8VGCR8")
+
+	var (
+		notified []domain.Match
+		down     = true
+	)
+	store := StoreFunc(func(ctx context.Context, match domain.Match) error {
+		if down {
+			return errors.New("database is down")
+		}
+		return repo.SaveMatch(ctx, match)
+	})
+	p := newTestPipeline(t, repo, channel, store, &notified)
+
+	if err := p.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.Matches()) != 0 {
+		t.Fatalf("saved %d matches with the database down", len(repo.Matches()))
+	}
+
+	down = false
+	if err := p.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.Matches()) != 2 || len(notified) != 2 {
+		t.Errorf("second run saved %d and notified %d matches, want 2 and 2", len(repo.Matches()), len(notified))
+	}
+}
+
+func TestCursorKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		sourceID int64
+		account  string
+		want     string
+	}{
+		{name: "channel", sourceID: -1000000000001, account: "+5511", want: "cursor:s1:-1000000000001"},
+		{name: "legacy group", sourceID: -42, account: "+5511", want: "cursor:s1:-42:+5511"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CursorKey("s1", tt.sourceID, tt.account); got != tt.want {
+				t.Errorf("CursorKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
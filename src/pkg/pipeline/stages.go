@@ -0,0 +1,134 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"bot-telegram/src/internal/domain"
+	"bot-telegram/src/pkg/telegram"
+
+	"github.com/gotd/td/tg"
+)
+
+// Nomes dos estágios, usados em StageError.
+const (
+	StageLoadSessions   = "load-sessions"
+	StageResolveSources = "resolve-sources"
+	StageFetchMessages  = "fetch-messages"
+	StageMatch          = "match"
+	StageResolveLinks   = "resolve-links"
+	StagePriceHistory   = "price-history"
+	StagePersist        = "persist"
+	StageNotify         = "notify"
+)
+
+// Job é uma sessão com os produtos que ela procura.
+type Job struct {
+	Session  domain.Session
+	Products []domain.Product
+}
+
+type sourceJob struct {
+	Job
+	Source telegram.Source
+}
+
+type messageJob struct {
+	sourceJob
+	Message *tg.Message
+}
+
+type matchJob struct {
+	domain.Match
+	// stores são as lojas aceitas pela sessão; vazio aceita todas.
+	stores []string
+}
+
+type SessionLoader interface {
+	LoadSessions(ctx context.Context) ([]Job, error)
+}
+
+type SessionLoaderFunc func(ctx context.Context) ([]Job, error)
+
+func (f SessionLoaderFunc) LoadSessions(ctx context.Context) ([]Job, error) { return f(ctx) }
+
+type SourceResolver interface {
+	ResolveSources(ctx context.Context, session domain.Session) ([]telegram.Source, error)
+}
+
+type SourceResolverFunc func(ctx context.Context, session domain.Session) ([]telegram.Source, error)
+
+func (f SourceResolverFunc) ResolveSources(ctx context.Context, session domain.Session) ([]telegram.Source, error) {
+	return f(ctx, session)
+}
+
+// MessageFetcher retorna as mensagens da fonte com ID maior que minID (0
+// quando a fonte nunca foi lida).
+type MessageFetcher interface {
+	FetchMessages(ctx context.Context, source telegram.Source, minID int) ([]*tg.Message, error)
+}
+
+type MessageFetcherFunc func(ctx context.Context, source telegram.Source, minID int) ([]*tg.Message, error)
+
+func (f MessageFetcherFunc) FetchMessages(ctx context.Context, source telegram.Source, minID int) ([]*tg.Message, error) {
+	return f(ctx, source, minID)
+}
+
+type Matcher interface {
+	Match(product domain.Product, message *tg.Message) (bool, error)
+}
+
+// Deduper diz se um match já foi visto, marcando-o como visto.
+type Deduper interface {
+	Seen(match domain.Match) bool
+	// Forget desmarca um match que não pôde ser gravado, para ele não ser
+	// descartado quando for lido de novo.
+	Forget(match domain.Match)
+}
+
+// LinkResolver retorna a URL para onde o link aponta, expandindo encurtadores.
+type LinkResolver interface {
+	Resolve(ctx context.Context, link string) (string, error)
+}
+
+type LinkResolverFunc func(ctx context.Context, link string) (string, error)
+
+func (f LinkResolverFunc) Resolve(ctx context.Context, link string) (string, error) {
+	return f(ctx, link)
+}
+
+type Store = domain.MatchRepository
+
+type StoreFunc func(ctx context.Context, match domain.Match) error
+
+func (f StoreFunc) SaveMatch(ctx context.Context, match domain.Match) error { return f(ctx, match) }
+
+type Notifier interface {
+	Notify(ctx context.Context, match domain.Match) error
+}
+
+type NotifierFunc func(ctx context.Context, match domain.Match) error
+
+func (f NotifierFunc) Notify(ctx context.Context, match domain.Match) error { return f(ctx, match) }
+
+// StageError é passado a Config.OnError quando um item falha em um estágio.
+type StageError struct {
+	Stage     string
+	SessionID string
+	SourceID  int64
+	Err       error
+}
+
+func (e *StageError) Error() string {
+	if e.SourceID != 0 {
+		return fmt.Sprintf("[%s] session %s source %d: %v", e.Stage, e.SessionID, e.SourceID, e.Err)
+	}
+	if e.SessionID != "" {
+		return fmt.Sprintf("[%s] session %s: %v", e.Stage, e.SessionID, e.Err)
+	}
+	return fmt.Sprintf("[%s] %v", e.Stage, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
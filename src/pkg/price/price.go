@@ -0,0 +1,71 @@
+// Package price encontra o preço da oferta no texto do post.
+package price
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	amountPattern = regexp.MustCompile(`R\$\s*(\d{1,3}(?:\.\d{3})+|\d+)(?:,(\d{1,2}))?`)
+	// "10x de R$ 19,90" é parcela.
+	installmentBefore = regexp.MustCompile(`(?i)\d+\s*x\s*(?:de\s*)?$`)
+	// "De R$ 199" é o preço antigo.
+	listBefore = regexp.MustCompile(`(?i)(?:^|\W)de\s*:?\s*$`)
+	saleBefore = regexp.MustCompile(`(?i)(?:por|apenas|s[oó]|hoje|agora|pre[cç]o)\s*:?\s*$`)
+	// "R$ 50 OFF", "R$ 20 de desconto" e "R$ 10 de cashback" não são o preço.
+	discountAfter = regexp.MustCompile(`(?i)^\s*(?:off|de\s+desconto|de\s+cashback|em\s+cashback|de\s+volta)`)
+)
+
+// Extract returns the sale price in text: the amount after "por"/"apenas",
+// otherwise the lowest amount that is not a list price, installment or
+// discount. It reports false when text has no price.
+func Extract(text string) (float64, bool) {
+	var sale, other, list []float64
+
+	for _, m := range amountPattern.FindAllStringSubmatchIndex(text, -1) {
+		before := text[max(0, m[0]-16):m[0]]
+		after := text[m[1]:]
+		if installmentBefore.MatchString(before) || discountAfter.MatchString(after) {
+			continue
+		}
+
+		value, ok := parse(text[m[2]:m[3]], text[max(m[4], 0):max(m[5], 0)])
+		if !ok {
+			continue
+		}
+
+		switch {
+		case saleBefore.MatchString(before):
+			sale = append(sale, value)
+		case listBefore.MatchString(before):
+			list = append(list, value)
+		default:
+			other = append(other, value)
+		}
+	}
+
+	for _, candidates := range [][]float64{sale, other, list} {
+		if len(candidates) > 0 {
+			lowest := candidates[0]
+			for _, v := range candidates[1:] {
+				lowest = min(lowest, v)
+			}
+			return lowest, true
+		}
+	}
+	return 0, false
+}
+
+func parse(integer, cents string) (float64, bool) {
+	s := strings.ReplaceAll(integer, ".", "")
+	if cents != "" {
+		s += "." + cents
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value <= 0 {
+		return 0, false
+	}
+	return value, true
+}
@@ -0,0 +1,30 @@
+package price
+
+import "testing"
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		text   string
+		want   float64
+		wantOK bool
+	}{
+		{"SSD 1TB por R$ 299,90", 299.90, true},
+		{"De R$ 399 por R$ 299", 299, true},
+		{"De: R$ 1.299,00\nPor: R$ 999,00", 999, true},
+		{"R$ 1.299 ou 10x de R$ 129,90", 1299, true},
+		{"Apenas R$49,9 no Pix", 49.9, true},
+		{"R$ 450 à vista, R$ 499 no cartão", 450, true},
+		{"Cupom de R$ 50 OFF, sai por R$ 200", 200, true},
+		{"R$ 20 de cashback no SSD de R$ 300", 300, true},
+		{"De R$ 199", 199, true},
+		{"SSD 1TB com 20% de desconto", 0, false},
+		{"R$ 0,00", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Extract(tt.text)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("Extract(%q) = %v, %v; want %v, %v", tt.text, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
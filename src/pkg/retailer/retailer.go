@@ -0,0 +1,171 @@
+// Package retailer reconhece a loja de um link de oferta, extrai o ID do
+// produto na loja e limpa os parâmetros de rastreio e de afiliado.
+package retailer
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Retailer is a store deals link to.
+type Retailer struct {
+	// ID is the store identifier used in sessions and matches, e.g. "amazon".
+	ID   string
+	Name string
+	// Hosts are the domains of the store, including its shorteners;
+	// subdomains match too.
+	Hosts []string
+	// ProductID returns the native product identifier in u, or "".
+	ProductID func(u *url.URL) string
+	// Keep are the query parameters that identify the product and survive
+	// Clean; every other parameter is dropped.
+	Keep []string
+	// Canonical builds the product URL from its ID; optional.
+	Canonical func(u *url.URL, productID string) string
+}
+
+// Offer is a link resolved to its store and product.
+type Offer struct {
+	Store     string
+	ProductID string
+	// URL is the link without tracking parameters.
+	URL string
+}
+
+// Key identifies the product across channels, or is "" when the product is
+// unknown.
+func (o Offer) Key() string {
+	if o.Store == "" || o.ProductID == "" {
+		return ""
+	}
+	return o.Store + ":" + o.ProductID
+}
+
+type Registry struct {
+	retailers []Retailer
+}
+
+func NewRegistry(retailers ...Retailer) *Registry {
+	return &Registry{retailers: retailers}
+}
+
+// Default knows the stores most deals channels link to.
+var Default = NewRegistry(Amazon, MercadoLivre, Shopee, Magalu, AliExpress, KaBuM)
+
+// Lookup returns the retailer that owns host.
+func (r *Registry) Lookup(host string) (Retailer, bool) {
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+	for _, retailer := range r.retailers {
+		for _, h := range retailer.Hosts {
+			if host == h || strings.HasSuffix(host, "."+h) {
+				return retailer, true
+			}
+		}
+	}
+	return Retailer{}, false
+}
+
+// Store returns the ID of the store link belongs to, or "".
+func (r *Registry) Store(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	retailer, _ := r.Lookup(u.Hostname())
+	return retailer.ID
+}
+
+// Mention returns the ID of the first store named in text, or "". It is the
+// fallback for posts without links, like "Cupom Amazon: PROMO10".
+func (r *Registry) Mention(text string) string {
+	text = strings.ToLower(text)
+	for _, retailer := range r.retailers {
+		for _, name := range []string{retailer.ID, retailer.Name} {
+			if containsWord(text, strings.ToLower(name)) {
+				return retailer.ID
+			}
+		}
+	}
+	return ""
+}
+
+func containsWord(text, word string) bool {
+	for i := 0; ; {
+		j := strings.Index(text[i:], word)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(word)
+		if (start == 0 || !isWordByte(text[start-1])) && (end == len(text) || !isWordByte(text[end])) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+func isWordByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= '0' && b <= '9'
+}
+
+// Parse returns the store and product of link with the tracking parameters
+// removed. Links of unknown stores keep their store and product empty.
+func (r *Registry) Parse(link string) (Offer, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return Offer{}, err
+	}
+	u.Fragment = ""
+
+	retailer, ok := r.Lookup(u.Hostname())
+	if !ok {
+		u.RawQuery = stripTracking(u.Query()).Encode()
+		return Offer{URL: u.String()}, nil
+	}
+
+	offer := Offer{Store: retailer.ID}
+	if retailer.ProductID != nil {
+		offer.ProductID = retailer.ProductID(u)
+	}
+
+	query := url.Values{}
+	for _, key := range retailer.Keep {
+		if v, ok := u.Query()[key]; ok {
+			query[key] = v
+		}
+	}
+	u.RawQuery = query.Encode()
+	offer.URL = u.String()
+	if retailer.Canonical != nil && offer.ProductID != "" {
+		offer.URL = retailer.Canonical(u, offer.ProductID)
+	}
+
+	return offer, nil
+}
+
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "igshid": true, "ref": true, "tag": true,
+	"aff_id": true, "affiliate": true, "partner_id": true, "mkt_source": true,
+}
+
+// stripTracking remove os parâmetros comuns de rastreio de lojas desconhecidas.
+func stripTracking(query url.Values) url.Values {
+	for key := range query {
+		lower := strings.ToLower(key)
+		if strings.HasPrefix(lower, "utm_") || trackingParams[lower] {
+			query.Del(key)
+		}
+	}
+	return query
+}
+
+func pathID(pattern string) func(u *url.URL) string {
+	re := regexp.MustCompile(pattern)
+	return func(u *url.URL) string {
+		m := re.FindStringSubmatch(u.Path)
+		if m == nil {
+			return ""
+		}
+		return strings.Join(m[1:], ".")
+	}
+}
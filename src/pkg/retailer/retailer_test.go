@@ -0,0 +1,63 @@
+package retailer
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		link string
+		want Offer
+	}{
+		{
+			"https://www.amazon.com.br/SSD-Kingston/dp/B09B8VGCR8/ref=sr_1_1?tag=promo-20&th=1",
+			Offer{Store: "amazon", ProductID: "B09B8VGCR8", URL: "https://www.amazon.com.br/dp/B09B8VGCR8"},
+		},
+		{
+			"https://www.mercadolivre.com.br/ssd-kingston/p/MLB18502712?item_id=MLB2118563742&matt_tool=1",
+			Offer{Store: "mercadolivre", ProductID: "MLB2118563742", URL: "https://www.mercadolivre.com.br/ssd-kingston/p/MLB18502712?item_id=MLB2118563742"},
+		},
+		{
+			"https://produto.mercadolivre.com.br/MLB-1234567890-ssd-1tb-_JM#position=1",
+			Offer{Store: "mercadolivre", ProductID: "MLB1234567890", URL: "https://produto.mercadolivre.com.br/MLB-1234567890-ssd-1tb-_JM"},
+		},
+		{
+			"https://shopee.com.br/SSD-1TB-i.123456.7890123?sp_atk=abc",
+			Offer{Store: "shopee", ProductID: "123456.7890123", URL: "https://shopee.com.br/SSD-1TB-i.123456.7890123"},
+		},
+		{
+			"https://www.magazineluiza.com.br/ssd-1tb/p/237465800/in/ssdi/?utm_source=x",
+			Offer{Store: "magalu", ProductID: "237465800", URL: "https://www.magazineluiza.com.br/ssd-1tb/p/237465800/in/ssdi/"},
+		},
+		{
+			"https://pt.aliexpress.com/item/1005004301.html?aff_fcid=x",
+			Offer{Store: "aliexpress", ProductID: "1005004301", URL: "https://pt.aliexpress.com/item/1005004301.html"},
+		},
+		{
+			"https://www.kabum.com.br/produto/123456/ssd",
+			Offer{Store: "kabum", ProductID: "123456", URL: "https://www.kabum.com.br/produto/123456/ssd"},
+		},
+		{
+			"https://loja.example.com/ssd?utm_source=telegram&id=42&ref=canal",
+			Offer{URL: "https://loja.example.com/ssd?id=42"},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := Default.Parse(tt.link)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.link, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.link, got, tt.want)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	if got := (Offer{Store: "amazon", ProductID: "B09B8VGCR8"}).Key(); got != "amazon:B09B8VGCR8" {
+		t.Errorf("Key() = %q", got)
+	}
+	if got := (Offer{Store: "amazon"}).Key(); got != "" {
+		t.Errorf("Key() without product = %q, want empty", got)
+	}
+}
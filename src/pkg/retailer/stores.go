@@ -0,0 +1,91 @@
+package retailer
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var Amazon = Retailer{
+	ID:        "amazon",
+	Name:      "Amazon",
+	Hosts:     []string{"amazon.com.br", "amazon.com", "amzn.to", "amzn.com", "a.co"},
+	ProductID: pathID(`/(?:dp|gp/product|gp/aw/d|exec/obidos/ASIN|o/ASIN)/([A-Z0-9]{10})`),
+	// O resto do caminho (/ref=..., título) é só rastreio.
+	Canonical: func(u *url.URL, asin string) string {
+		return "https://" + u.Host + "/dp/" + asin
+	},
+}
+
+var mlbPattern = regexp.MustCompile(`(?i)MLB-?(\d+)`)
+
+var MercadoLivre = Retailer{
+	ID:    "mercadolivre",
+	Name:  "Mercado Livre",
+	Hosts: []string{"mercadolivre.com.br", "mercadolivre.com", "mercadolibre.com", "meli.la"},
+	ProductID: func(u *url.URL) string {
+		// Links de catálogo (/p/MLB123) e de anúncio (MLB-123-titulo) ou
+		// o item escolhido no catálogo (?item_id=MLB123).
+		for _, s := range []string{u.Query().Get("item_id"), u.Path} {
+			if m := mlbPattern.FindStringSubmatch(s); m != nil {
+				return "MLB" + m[1]
+			}
+		}
+		return ""
+	},
+	Keep: []string{"item_id"},
+}
+
+var shopeeProduct = regexp.MustCompile(`/product/(\d+)/(\d+)`)
+
+var Shopee = Retailer{
+	ID:    "shopee",
+	Name:  "Shopee",
+	Hosts: []string{"shopee.com.br", "shope.ee"},
+	ProductID: func(u *url.URL) string {
+		// shopee.com.br/Nome-do-produto-i.<loja>.<item> ou /product/<loja>/<item>.
+		if i := strings.LastIndex(u.Path, "-i."); i >= 0 {
+			parts := strings.Split(u.Path[i+3:], ".")
+			if len(parts) >= 2 && isDigits(parts[0]) && isDigits(parts[1]) {
+				return parts[0] + "." + parts[1]
+			}
+		}
+		if m := shopeeProduct.FindStringSubmatch(u.Path); m != nil {
+			return m[1] + "." + m[2]
+		}
+		return ""
+	},
+}
+
+var Magalu = Retailer{
+	ID:        "magalu",
+	Name:      "Magazine Luiza",
+	Hosts:     []string{"magazineluiza.com.br", "magazinevoce.com.br", "magalu.com", "magalu.com.br", "magalu.lu"},
+	ProductID: pathID(`/p/([a-z0-9]{6,})(?:/|$)`),
+}
+
+var AliExpress = Retailer{
+	ID:        "aliexpress",
+	Name:      "AliExpress",
+	Hosts:     []string{"aliexpress.com", "aliexpress.us", "a.aliexpress.com"},
+	ProductID: pathID(`/item/(?:\d+/)?(\d+)\.html`),
+}
+
+var KaBuM = Retailer{
+	ID:        "kabum",
+	Name:      "KaBuM!",
+	Hosts:     []string{"kabum.com.br"},
+	ProductID: pathID(`/produto/(\d+)`),
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
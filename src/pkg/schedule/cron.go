@@ -0,0 +1,154 @@
+// Package schedule roda as sessões no cron_schedule de cada uma.
+package schedule
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-faster/errors"
+)
+
+// Cron is a parsed five-field cron expression (minute hour day month weekday).
+type Cron struct {
+	minute, hour, day, month, weekday uint64
+	// anyDay and anyWeekday are set when the field allows every value. As in
+	// cron, when both day and weekday are restricted, either one matching is
+	// enough; "*/2" is restricted.
+	anyDay, anyWeekday bool
+}
+
+var descriptors = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// Parse reads "*/30 * * * *", "0 8-22/2 * * 1-5", "@hourly", ...
+func Parse(spec string) (Cron, error) {
+	if expanded, ok := descriptors[strings.TrimSpace(spec)]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return Cron{}, errors.Errorf("cron %q: expected 5 fields, got %d", spec, len(fields))
+	}
+
+	var c Cron
+	var err error
+	if c.minute, err = parseField(fields[0], 0, 59); err != nil {
+		return Cron{}, errors.Wrapf(err, "cron %q: minute", spec)
+	}
+	if c.hour, err = parseField(fields[1], 0, 23); err != nil {
+		return Cron{}, errors.Wrapf(err, "cron %q: hour", spec)
+	}
+	if c.day, err = parseField(fields[2], 1, 31); err != nil {
+		return Cron{}, errors.Wrapf(err, "cron %q: day", spec)
+	}
+	if c.month, err = parseField(fields[3], 1, 12); err != nil {
+		return Cron{}, errors.Wrapf(err, "cron %q: month", spec)
+	}
+	if c.weekday, err = parseField(fields[4], 0, 7); err != nil {
+		return Cron{}, errors.Wrapf(err, "cron %q: weekday", spec)
+	}
+	// 7 também é domingo.
+	if c.weekday&(1<<7) != 0 {
+		c.weekday |= 1
+	}
+	c.anyDay = c.day == span(1, 31)
+	c.anyWeekday = c.weekday&span(0, 6) == span(0, 6)
+
+	return c, nil
+}
+
+func parseField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, errors.Errorf("invalid step in %q", part)
+			}
+			rng, step = part[:i], n
+		}
+
+		lo, hi := min, max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(a)
+			hi, err2 = strconv.Atoi(b)
+			if err1 != nil || err2 != nil {
+				return 0, errors.Errorf("invalid range %q", rng)
+			}
+		default:
+			n, err := strconv.Atoi(rng)
+			if err != nil {
+				return 0, errors.Errorf("invalid value %q", rng)
+			}
+			lo, hi = n, n
+			if step > 1 {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, errors.Errorf("%q out of range %d-%d", part, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// span returns the bits lo through hi.
+func span(lo, hi int) uint64 {
+	return (1<<(hi+1) - 1) &^ (1<<lo - 1)
+}
+
+// Next returns the first time after t that matches, or the zero time when
+// none does in the next five years (e.g. "0 0 31 2 *"). Fields are compared
+// with the wall clock of t's location.
+func (c Cron) Next(t time.Time) time.Time {
+	t = t.Add(-time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond())).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if c.month&(1<<int(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.hour&(1<<t.Hour()) == 0 {
+			// Truncate arredonda o instante em UTC, não o relógio: erra em
+			// fusos como +05:30.
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.minute&(1<<t.Minute()) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (c Cron) matchDay(t time.Time) bool {
+	day := c.day&(1<<t.Day()) != 0
+	weekday := c.weekday&(1<<int(t.Weekday())) != 0
+
+	if c.anyDay || c.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
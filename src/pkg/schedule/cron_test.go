@@ -0,0 +1,73 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec    string
+		wantErr bool
+	}{
+		{spec: "*/30 * * * *"},
+		{spec: "0 8-22/2 * * 1-5"},
+		{spec: "0 0 * * 7"},
+		{spec: "5,35 * 1,15 * *"},
+		{spec: "@hourly"},
+		{spec: "@daily"},
+		{spec: "* * * *", wantErr: true},
+		{spec: "60 * * * *", wantErr: true},
+		{spec: "0 24 * * *", wantErr: true},
+		{spec: "0 0 0 * *", wantErr: true},
+		{spec: "*/0 * * * *", wantErr: true},
+		{spec: "0 10-8 * * *", wantErr: true},
+		{spec: "a * * * *", wantErr: true},
+		{spec: "@yearly", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	saoPaulo := time.FixedZone("-03", -3*60*60)
+	kolkata := time.FixedZone("+0530", 5*60*60+30*60)
+
+	tests := []struct {
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{"*/30 * * * *", time.Date(2025, 3, 10, 10, 5, 30, 0, saoPaulo), time.Date(2025, 3, 10, 10, 30, 0, 0, saoPaulo)},
+		{"*/30 * * * *", time.Date(2025, 3, 10, 10, 30, 0, 0, saoPaulo), time.Date(2025, 3, 10, 11, 0, 0, 0, saoPaulo)},
+		{"@hourly", time.Date(2025, 3, 10, 23, 59, 0, 0, saoPaulo), time.Date(2025, 3, 11, 0, 0, 0, 0, saoPaulo)},
+		{"0 8-22/2 * * 1-5", time.Date(2025, 3, 14, 22, 0, 0, 0, saoPaulo), time.Date(2025, 3, 17, 8, 0, 0, 0, saoPaulo)},
+		{"0 0 1 * *", time.Date(2025, 12, 15, 0, 0, 0, 0, saoPaulo), time.Date(2026, 1, 1, 0, 0, 0, 0, saoPaulo)},
+		{"0 0 * * 7", time.Date(2025, 3, 10, 0, 0, 0, 0, saoPaulo), time.Date(2025, 3, 16, 0, 0, 0, 0, saoPaulo)},
+		// Horas inteiras no relógio local, não em UTC.
+		{"0 */2 * * *", time.Date(2025, 3, 10, 9, 10, 0, 0, kolkata), time.Date(2025, 3, 10, 10, 0, 0, 0, kolkata)},
+		{"30 9 * * *", time.Date(2025, 3, 10, 8, 45, 0, 0, kolkata), time.Date(2025, 3, 10, 9, 30, 0, 0, kolkata)},
+		// Dia e dia da semana restritos: basta um dos dois.
+		{"0 0 */2 * 1", time.Date(2025, 3, 10, 0, 0, 0, 0, saoPaulo), time.Date(2025, 3, 11, 0, 0, 0, 0, saoPaulo)},
+		{"0 0 */2 * 1", time.Date(2025, 3, 15, 0, 0, 0, 0, saoPaulo), time.Date(2025, 3, 17, 0, 0, 0, 0, saoPaulo)},
+		{"0 0 15 * 1", time.Date(2025, 3, 11, 0, 0, 0, 0, saoPaulo), time.Date(2025, 3, 15, 0, 0, 0, 0, saoPaulo)},
+		// Só o dia restrito.
+		{"0 0 15 * *", time.Date(2025, 3, 11, 0, 0, 0, 0, saoPaulo), time.Date(2025, 3, 15, 0, 0, 0, 0, saoPaulo)},
+		{"0 0 31 2 *", time.Date(2025, 3, 11, 0, 0, 0, 0, saoPaulo), time.Time{}},
+	}
+
+	for _, tt := range tests {
+		c, err := Parse(tt.spec)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.spec, err)
+		}
+		if got := c.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("Parse(%q).Next(%s) = %s, want %s", tt.spec, tt.from, got, tt.want)
+		}
+	}
+}
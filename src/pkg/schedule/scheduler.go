@@ -0,0 +1,132 @@
+package schedule
+
+import (
+	"context"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"bot-telegram/src/internal/domain"
+
+	"github.com/go-faster/errors"
+)
+
+// DefaultSpec is used by sessions without cron_schedule.
+const DefaultSpec = "0 */2 * * *"
+
+// Scheduler runs each session on its cron_schedule. Reload swaps the
+// sessions while it is running.
+type Scheduler struct {
+	// Run executes the sessions that are due. Runs never overlap: sessions
+	// that become due during a run go in the next one.
+	Run func(ctx context.Context, sessionIDs []string) error
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	reload  chan struct{}
+}
+
+type entry struct {
+	spec string
+	cron Cron
+	next time.Time
+}
+
+// Reload replaces the scheduled sessions. Sessions whose schedule didn't
+// change keep their next run. Sessions with an invalid cron_schedule are
+// left out and reported in the error.
+func (s *Scheduler) Reload(sessions []domain.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	entries := make(map[string]*entry, len(sessions))
+	var errs []error
+	for _, session := range sessions {
+		spec := session.CronSchedule
+		if spec == "" {
+			spec = DefaultSpec
+		}
+
+		if old, ok := s.entries[session.SessionId]; ok && old.spec == spec {
+			entries[session.SessionId] = old
+			continue
+		}
+
+		cron, err := Parse(spec)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "session %s", session.SessionId))
+			continue
+		}
+		entries[session.SessionId] = &entry{spec: spec, cron: cron, next: cron.Next(now)}
+	}
+	s.entries = entries
+
+	if s.reload == nil {
+		s.reload = make(chan struct{}, 1)
+	}
+	select {
+	case s.reload <- struct{}{}:
+	default:
+	}
+
+	return errors.Join(errs...)
+}
+
+// Start runs the sessions until ctx is done. Errors of a run are logged.
+func (s *Scheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	if s.reload == nil {
+		s.reload = make(chan struct{}, 1)
+	}
+	reload := s.reload
+	s.mu.Unlock()
+
+	for {
+		due, wait := s.due(time.Now())
+		if len(due) > 0 {
+			if err := s.Run(ctx, due); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				log.Printf("[SCHEDULE] %v", err)
+			}
+			continue
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-reload:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// due returns the sessions to run now and advances their next run, or how
+// long to wait for the next one.
+func (s *Scheduler) due(now time.Time) ([]string, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []string
+	wait := time.Hour
+	for id, e := range s.entries {
+		if e.next.IsZero() {
+			continue
+		}
+		if !e.next.After(now) {
+			due = append(due, id)
+			e.next = e.cron.Next(now)
+			continue
+		}
+		wait = min(wait, e.next.Sub(now))
+	}
+	slices.Sort(due)
+
+	return due, wait
+}
@@ -0,0 +1,139 @@
+package sqlstore
+
+import (
+	"context"
+	"embed"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-faster/errors"
+)
+
+//go:embed migrations
+var migrations embed.FS
+
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// Migrate connects to dsn and applies the pending migrations of dialect. It
+// returns the names of the migrations applied.
+func Migrate(ctx context.Context, dialect Dialect, dsn string) ([]string, error) {
+	r, err := connect(ctx, dialect, dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return r.Migrate(ctx)
+}
+
+// Migrate applies, in order and each in its own transaction, the migrations
+// not yet recorded in schema_migrations. It returns the names of the
+// migrations applied.
+func (r *Repository) Migrate(ctx context.Context) ([]string, error) {
+	if err := r.exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TEXT NOT NULL
+	)`); err != nil {
+		return nil, errors.Wrap(err, "[SQL] create schema_migrations")
+	}
+	for _, stmt := range r.dialect.migrationsSetup {
+		if err := r.exec(ctx, stmt); err != nil {
+			return nil, errors.Wrapf(err, "[SQL] %s", stmt)
+		}
+	}
+
+	pending, err := loadMigrations(r.dialect.migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]bool)
+	rows, err := r.query(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, errors.Wrap(err, "[SQL] list migrations")
+	}
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return nil, errors.Wrap(err, "[SQL] list migrations")
+		}
+		applied[version] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "[SQL] list migrations")
+	}
+
+	var names []string
+	for _, m := range pending {
+		if applied[m.version] {
+			continue
+		}
+		if err := r.apply(ctx, m); err != nil {
+			return names, errors.Wrapf(err, "[SQL] migration %s", m.name)
+		}
+		names = append(names, m.name)
+	}
+
+	return names, nil
+}
+
+func (r *Repository) apply(ctx context.Context, m migration) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.sql); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		r.rebind(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`),
+		m.version, m.name, now(),
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// loadMigrations reads dir/NNNN_name.sql sorted by version.
+func loadMigrations(dir string) ([]migration, error) {
+	entries, err := fs.ReadDir(migrations, dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "[SQL] read migrations")
+	}
+
+	var list []migration
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+
+		prefix, _, _ := strings.Cut(name, "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, errors.Errorf("[SQL] migration %s has no version prefix", name)
+		}
+
+		data, err := fs.ReadFile(migrations, path.Join(dir, name))
+		if err != nil {
+			return nil, errors.Wrap(err, "[SQL] read migrations")
+		}
+		list = append(list, migration{version: version, name: name, sql: string(data)})
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].version < list[j].version })
+	return list, nil
+}
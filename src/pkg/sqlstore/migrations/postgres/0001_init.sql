@@ -0,0 +1,76 @@
+CREATE TABLE sessions (
+    id            text PRIMARY KEY,
+    cron_schedule text NOT NULL DEFAULT '',
+    folder        text NOT NULL DEFAULT '',
+    provider_ids  jsonb NOT NULL DEFAULT '[]',
+    product_ids   jsonb NOT NULL DEFAULT '[]',
+    stores        jsonb NOT NULL DEFAULT '[]',
+    watch_coupons boolean NOT NULL DEFAULT false,
+    created_at    timestamptz NOT NULL DEFAULT now(),
+    updated_at    timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE products (
+    id         text PRIMARY KEY,
+    title      text NOT NULL DEFAULT '',
+    name       text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE providers (
+    id                 text PRIMARY KEY,
+    kind               text NOT NULL,
+    title              text NOT NULL DEFAULT '',
+    username           text NOT NULL DEFAULT '',
+    description        text NOT NULL DEFAULT '',
+    participants_count integer NOT NULL DEFAULT 0,
+    broadcast          boolean NOT NULL DEFAULT false,
+    megagroup          boolean NOT NULL DEFAULT false,
+    updated_at         timestamptz
+);
+
+CREATE TABLE matches (
+    id             bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    session_id     text NOT NULL,
+    product_id     text NOT NULL DEFAULT '',
+    product_name   text NOT NULL DEFAULT '',
+    source_id      bigint NOT NULL,
+    message_id     integer NOT NULL,
+    text           text NOT NULL DEFAULT '',
+    links          jsonb NOT NULL DEFAULT '[]',
+    store          text NOT NULL DEFAULT '',
+    offer_id       text NOT NULL DEFAULT '',
+    coupons        jsonb NOT NULL DEFAULT '[]',
+    price          double precision NOT NULL DEFAULT 0,
+    historical_low boolean NOT NULL DEFAULT false,
+    below_average  boolean NOT NULL DEFAULT false,
+    posted_at      timestamptz NOT NULL
+);
+CREATE INDEX matches_session_idx ON matches (session_id, posted_at);
+
+CREATE TABLE price_history (
+    id          bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    product_id  text NOT NULL,
+    offer_id    text NOT NULL DEFAULT '',
+    store       text NOT NULL DEFAULT '',
+    price       double precision NOT NULL,
+    source_id   bigint NOT NULL,
+    message_id  integer NOT NULL,
+    recorded_at timestamptz NOT NULL
+);
+CREATE INDEX price_history_offer_idx ON price_history (offer_id, recorded_at);
+CREATE INDEX price_history_product_idx ON price_history (product_id, store, recorded_at);
+
+CREATE TABLE runs (
+    id          bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    started_at  timestamptz NOT NULL,
+    finished_at timestamptz NOT NULL,
+    matches     integer NOT NULL DEFAULT 0,
+    error       text NOT NULL DEFAULT ''
+);
+
+CREATE TABLE bot_state (
+    key   text PRIMARY KEY,
+    value bytea NOT NULL
+);
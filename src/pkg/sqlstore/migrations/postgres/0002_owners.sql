@@ -0,0 +1,15 @@
+ALTER TABLE sessions ADD COLUMN user_id text NOT NULL DEFAULT '';
+ALTER TABLE products ADD COLUMN user_id text NOT NULL DEFAULT '';
+ALTER TABLE matches ADD COLUMN user_id text NOT NULL DEFAULT '';
+CREATE INDEX sessions_user_idx ON sessions (user_id);
+CREATE INDEX products_user_idx ON products (user_id);
+CREATE INDEX matches_user_idx ON matches (user_id, posted_at);
+
+CREATE TABLE notification_channels (
+    id      text PRIMARY KEY,
+    user_id text NOT NULL,
+    kind    text NOT NULL,
+    target  text NOT NULL,
+    enabled boolean NOT NULL DEFAULT true
+);
+CREATE INDEX notification_channels_user_idx ON notification_channels (user_id);
@@ -0,0 +1,5 @@
+-- edited_at passa a ser timestamptz, como no Supabase; NULL é post nunca editado.
+
+ALTER TABLE matches ALTER COLUMN edited_at DROP DEFAULT;
+ALTER TABLE matches ALTER COLUMN edited_at DROP NOT NULL;
+ALTER TABLE matches ALTER COLUMN edited_at TYPE timestamptz USING NULLIF(edited_at, '')::timestamptz;
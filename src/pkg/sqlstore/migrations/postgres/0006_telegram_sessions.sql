@@ -0,0 +1,7 @@
+-- Sessão MTProto de cada conta (TELEGRAM_SESSION_STORAGE=db), cifrada pelo bot.
+
+CREATE TABLE telegram_sessions (
+    id         text PRIMARY KEY,
+    data       bytea NOT NULL,
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
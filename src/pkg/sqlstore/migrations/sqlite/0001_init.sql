@@ -0,0 +1,76 @@
+CREATE TABLE sessions (
+    id            TEXT PRIMARY KEY,
+    cron_schedule TEXT NOT NULL DEFAULT '',
+    folder        TEXT NOT NULL DEFAULT '',
+    provider_ids  TEXT NOT NULL DEFAULT '[]',
+    product_ids   TEXT NOT NULL DEFAULT '[]',
+    stores        TEXT NOT NULL DEFAULT '[]',
+    watch_coupons INTEGER NOT NULL DEFAULT 0,
+    created_at    TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    updated_at    TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+
+CREATE TABLE products (
+    id         TEXT PRIMARY KEY,
+    title      TEXT NOT NULL DEFAULT '',
+    name       TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+
+CREATE TABLE providers (
+    id                 TEXT PRIMARY KEY,
+    kind               TEXT NOT NULL,
+    title              TEXT NOT NULL DEFAULT '',
+    username           TEXT NOT NULL DEFAULT '',
+    description        TEXT NOT NULL DEFAULT '',
+    participants_count INTEGER NOT NULL DEFAULT 0,
+    broadcast          INTEGER NOT NULL DEFAULT 0,
+    megagroup          INTEGER NOT NULL DEFAULT 0,
+    updated_at         TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE matches (
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id     TEXT NOT NULL,
+    product_id     TEXT NOT NULL DEFAULT '',
+    product_name   TEXT NOT NULL DEFAULT '',
+    source_id      INTEGER NOT NULL,
+    message_id     INTEGER NOT NULL,
+    text           TEXT NOT NULL DEFAULT '',
+    links          TEXT NOT NULL DEFAULT '[]',
+    store          TEXT NOT NULL DEFAULT '',
+    offer_id       TEXT NOT NULL DEFAULT '',
+    coupons        TEXT NOT NULL DEFAULT '[]',
+    price          REAL NOT NULL DEFAULT 0,
+    historical_low INTEGER NOT NULL DEFAULT 0,
+    below_average  INTEGER NOT NULL DEFAULT 0,
+    posted_at      TEXT NOT NULL
+);
+CREATE INDEX matches_session_idx ON matches (session_id, posted_at);
+
+CREATE TABLE price_history (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    product_id  TEXT NOT NULL,
+    offer_id    TEXT NOT NULL DEFAULT '',
+    store       TEXT NOT NULL DEFAULT '',
+    price       REAL NOT NULL,
+    source_id   INTEGER NOT NULL,
+    message_id  INTEGER NOT NULL,
+    recorded_at TEXT NOT NULL
+);
+CREATE INDEX price_history_offer_idx ON price_history (offer_id, recorded_at);
+CREATE INDEX price_history_product_idx ON price_history (product_id, store, recorded_at);
+
+CREATE TABLE runs (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    started_at  TEXT NOT NULL,
+    finished_at TEXT NOT NULL,
+    matches     INTEGER NOT NULL DEFAULT 0,
+    error       TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE bot_state (
+    key   TEXT PRIMARY KEY,
+    value BLOB NOT NULL
+);
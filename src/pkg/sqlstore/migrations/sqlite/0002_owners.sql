@@ -0,0 +1,15 @@
+ALTER TABLE sessions ADD COLUMN user_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE products ADD COLUMN user_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE matches ADD COLUMN user_id TEXT NOT NULL DEFAULT '';
+CREATE INDEX sessions_user_idx ON sessions (user_id);
+CREATE INDEX products_user_idx ON products (user_id);
+CREATE INDEX matches_user_idx ON matches (user_id, posted_at);
+
+CREATE TABLE notification_channels (
+    id      TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    kind    TEXT NOT NULL,
+    target  TEXT NOT NULL,
+    enabled INTEGER NOT NULL DEFAULT 1
+);
+CREATE INDEX notification_channels_user_idx ON notification_channels (user_id);
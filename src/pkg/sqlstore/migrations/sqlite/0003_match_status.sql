@@ -0,0 +1,6 @@
+-- Estado do post de cada match: active, sold_out, deleted ou price_changed.
+
+ALTER TABLE matches ADD COLUMN status TEXT NOT NULL DEFAULT 'active';
+ALTER TABLE matches ADD COLUMN previous_price REAL NOT NULL DEFAULT 0;
+ALTER TABLE matches ADD COLUMN edited_at TEXT NOT NULL DEFAULT '';
+CREATE INDEX matches_message_idx ON matches (source_id, message_id);
@@ -0,0 +1,7 @@
+-- edited_at passa a aceitar NULL (post nunca editado), como no Postgres.
+-- O SQLite não altera colunas: a coluna é recriada.
+
+ALTER TABLE matches ADD COLUMN edited_at_new TEXT;
+UPDATE matches SET edited_at_new = NULLIF(edited_at, '');
+ALTER TABLE matches DROP COLUMN edited_at;
+ALTER TABLE matches RENAME COLUMN edited_at_new TO edited_at;
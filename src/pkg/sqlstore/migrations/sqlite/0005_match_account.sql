@@ -0,0 +1,3 @@
+-- Conta que leu o post: em grupos comuns os IDs das mensagens são de cada conta.
+
+ALTER TABLE matches ADD COLUMN account TEXT NOT NULL DEFAULT '';
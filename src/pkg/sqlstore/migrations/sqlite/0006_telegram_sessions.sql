@@ -0,0 +1,7 @@
+-- Sessão MTProto de cada conta (TELEGRAM_SESSION_STORAGE=db), cifrada pelo bot.
+
+CREATE TABLE telegram_sessions (
+    id         TEXT PRIMARY KEY,
+    data       BLOB NOT NULL,
+    updated_at TEXT NOT NULL
+);
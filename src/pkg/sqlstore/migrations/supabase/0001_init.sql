@@ -0,0 +1,133 @@
+-- Tabelas usadas pelo bot através do PostgREST (src/pkg/supabase).
+--
+-- RLS: usuários autenticados (o dashboard) leem tudo e cadastram sessões e
+-- produtos; o usuário do bot, com app_metadata.role = 'bot', escreve nas
+-- demais tabelas. A service role ignora o RLS. As sessões do Telegram só são
+-- acessíveis pelo bot.
+
+CREATE OR REPLACE FUNCTION public.is_bot() RETURNS boolean
+LANGUAGE sql STABLE AS $$
+    SELECT coalesce(auth.jwt() -> 'app_metadata' ->> 'role', '') = 'bot'
+$$;
+
+CREATE TABLE public.sessions (
+    id            text PRIMARY KEY DEFAULT gen_random_uuid()::text,
+    cron_schedule text NOT NULL DEFAULT '',
+    folder        text NOT NULL DEFAULT '',
+    provider_ids  text[] NOT NULL DEFAULT '{}',
+    product_ids   text[] NOT NULL DEFAULT '{}',
+    stores        text[] NOT NULL DEFAULT '{}',
+    watch_coupons boolean NOT NULL DEFAULT false,
+    created_at    timestamptz NOT NULL DEFAULT now(),
+    updated_at    timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE public.products (
+    id         text PRIMARY KEY DEFAULT gen_random_uuid()::text,
+    title      text NOT NULL DEFAULT '',
+    name       text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE public.providers (
+    id                 text PRIMARY KEY,
+    kind               text NOT NULL,
+    title              text NOT NULL DEFAULT '',
+    username           text NOT NULL DEFAULT '',
+    description        text NOT NULL DEFAULT '',
+    participants_count integer NOT NULL DEFAULT 0,
+    broadcast          boolean NOT NULL DEFAULT false,
+    megagroup          boolean NOT NULL DEFAULT false,
+    updated_at         timestamptz
+);
+
+CREATE TABLE public.matches (
+    id             bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    session_id     text NOT NULL REFERENCES public.sessions (id) ON DELETE CASCADE,
+    product_id     text NOT NULL DEFAULT '',
+    product_name   text NOT NULL DEFAULT '',
+    source_id      bigint NOT NULL,
+    message_id     integer NOT NULL,
+    text           text NOT NULL DEFAULT '',
+    links          text[] NOT NULL DEFAULT '{}',
+    store          text NOT NULL DEFAULT '',
+    offer_id       text NOT NULL DEFAULT '',
+    coupons        jsonb NOT NULL DEFAULT '[]',
+    price          double precision NOT NULL DEFAULT 0,
+    historical_low boolean NOT NULL DEFAULT false,
+    below_average  boolean NOT NULL DEFAULT false,
+    posted_at      timestamptz NOT NULL,
+    created_at     timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX matches_session_idx ON public.matches (session_id, posted_at DESC);
+CREATE INDEX matches_offer_idx ON public.matches (offer_id) WHERE offer_id <> '';
+
+CREATE TABLE public.price_history (
+    id          bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    product_id  text NOT NULL,
+    offer_id    text NOT NULL DEFAULT '',
+    store       text NOT NULL DEFAULT '',
+    price       double precision NOT NULL,
+    source_id   bigint NOT NULL,
+    message_id  integer NOT NULL,
+    recorded_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX price_history_offer_idx ON public.price_history (offer_id, recorded_at);
+CREATE INDEX price_history_product_idx ON public.price_history (product_id, store, recorded_at);
+
+CREATE TABLE public.runs (
+    id          bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    started_at  timestamptz NOT NULL,
+    finished_at timestamptz NOT NULL,
+    matches     integer NOT NULL DEFAULT 0,
+    error       text NOT NULL DEFAULT ''
+);
+
+CREATE TABLE public.bot_state (
+    key   text PRIMARY KEY,
+    value text NOT NULL
+);
+
+CREATE TABLE public.telegram_sessions (
+    id         text PRIMARY KEY,
+    data       text NOT NULL,
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+ALTER TABLE public.sessions          ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.products          ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.providers         ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.matches           ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.price_history     ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.runs              ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_state         ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.telegram_sessions ENABLE ROW LEVEL SECURITY;
+
+-- Dashboard.
+CREATE POLICY "dashboard manages sessions" ON public.sessions
+    FOR ALL TO authenticated USING (true) WITH CHECK (true);
+CREATE POLICY "dashboard manages products" ON public.products
+    FOR ALL TO authenticated USING (true) WITH CHECK (true);
+CREATE POLICY "dashboard reads providers" ON public.providers
+    FOR SELECT TO authenticated USING (true);
+CREATE POLICY "dashboard reads matches" ON public.matches
+    FOR SELECT TO authenticated USING (true);
+CREATE POLICY "dashboard reads price history" ON public.price_history
+    FOR SELECT TO authenticated USING (true);
+CREATE POLICY "dashboard reads runs" ON public.runs
+    FOR SELECT TO authenticated USING (true);
+
+-- Bot.
+CREATE POLICY "bot writes providers" ON public.providers
+    FOR ALL TO authenticated USING (public.is_bot()) WITH CHECK (public.is_bot());
+CREATE POLICY "bot writes matches" ON public.matches
+    FOR INSERT TO authenticated WITH CHECK (public.is_bot());
+CREATE POLICY "bot writes price history" ON public.price_history
+    FOR INSERT TO authenticated WITH CHECK (public.is_bot());
+CREATE POLICY "bot writes runs" ON public.runs
+    FOR INSERT TO authenticated WITH CHECK (public.is_bot());
+CREATE POLICY "bot keeps state" ON public.bot_state
+    FOR ALL TO authenticated USING (public.is_bot()) WITH CHECK (public.is_bot());
+CREATE POLICY "bot keeps telegram sessions" ON public.telegram_sessions
+    FOR ALL TO authenticated USING (public.is_bot()) WITH CHECK (public.is_bot());
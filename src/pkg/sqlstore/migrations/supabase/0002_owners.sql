@@ -0,0 +1,40 @@
+-- Cada usuário do dashboard só vê as próprias sessões, produtos, matches e
+-- canais de notificação. O bot continua lendo tudo.
+
+ALTER TABLE public.sessions ADD COLUMN user_id uuid REFERENCES auth.users (id) ON DELETE CASCADE DEFAULT auth.uid();
+ALTER TABLE public.products ADD COLUMN user_id uuid REFERENCES auth.users (id) ON DELETE CASCADE DEFAULT auth.uid();
+ALTER TABLE public.matches ADD COLUMN user_id uuid REFERENCES auth.users (id) ON DELETE CASCADE;
+CREATE INDEX sessions_user_idx ON public.sessions (user_id);
+CREATE INDEX products_user_idx ON public.products (user_id);
+CREATE INDEX matches_user_idx ON public.matches (user_id, posted_at DESC);
+
+CREATE TABLE public.notification_channels (
+    id         text PRIMARY KEY DEFAULT gen_random_uuid()::text,
+    user_id    uuid NOT NULL REFERENCES auth.users (id) ON DELETE CASCADE DEFAULT auth.uid(),
+    kind       text NOT NULL CHECK (kind IN ('webhook', 'telegram')),
+    target     text NOT NULL,
+    enabled    boolean NOT NULL DEFAULT true,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX notification_channels_user_idx ON public.notification_channels (user_id);
+ALTER TABLE public.notification_channels ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY "dashboard manages sessions" ON public.sessions;
+DROP POLICY "dashboard manages products" ON public.products;
+DROP POLICY "dashboard reads matches" ON public.matches;
+
+CREATE POLICY "owner manages sessions" ON public.sessions
+    FOR ALL TO authenticated USING (user_id = auth.uid()) WITH CHECK (user_id = auth.uid());
+CREATE POLICY "owner manages products" ON public.products
+    FOR ALL TO authenticated USING (user_id = auth.uid()) WITH CHECK (user_id = auth.uid());
+CREATE POLICY "owner reads matches" ON public.matches
+    FOR SELECT TO authenticated USING (user_id = auth.uid());
+CREATE POLICY "owner manages notification channels" ON public.notification_channels
+    FOR ALL TO authenticated USING (user_id = auth.uid()) WITH CHECK (user_id = auth.uid());
+
+CREATE POLICY "bot reads sessions" ON public.sessions
+    FOR SELECT TO authenticated USING (public.is_bot());
+CREATE POLICY "bot reads products" ON public.products
+    FOR SELECT TO authenticated USING (public.is_bot());
+CREATE POLICY "bot reads notification channels" ON public.notification_channels
+    FOR SELECT TO authenticated USING (public.is_bot());
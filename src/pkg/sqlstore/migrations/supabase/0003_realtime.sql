@@ -0,0 +1,4 @@
+-- O bot escuta as mudanças de sessions e products pelo Realtime para
+-- recarregar o agendamento sem reiniciar.
+
+ALTER PUBLICATION supabase_realtime ADD TABLE public.sessions, public.products;
@@ -0,0 +1,13 @@
+-- Estado do post de cada match: active, sold_out, deleted ou price_changed.
+-- O bot atualiza os matches quando o post é editado ou apagado.
+
+ALTER TABLE public.matches ADD COLUMN status text NOT NULL DEFAULT 'active'
+    CHECK (status IN ('active', 'sold_out', 'deleted', 'price_changed'));
+ALTER TABLE public.matches ADD COLUMN previous_price double precision NOT NULL DEFAULT 0;
+ALTER TABLE public.matches ADD COLUMN edited_at timestamptz;
+CREATE INDEX matches_message_idx ON public.matches (source_id, message_id);
+
+CREATE POLICY "bot reads matches" ON public.matches
+    FOR SELECT TO authenticated USING (public.is_bot());
+CREATE POLICY "bot updates matches" ON public.matches
+    FOR UPDATE TO authenticated USING (public.is_bot()) WITH CHECK (public.is_bot());
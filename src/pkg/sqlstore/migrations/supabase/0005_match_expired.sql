@@ -0,0 +1,5 @@
+-- Oferta encerrada ("promoção encerrada", post riscado) além de esgotada.
+
+ALTER TABLE public.matches DROP CONSTRAINT matches_status_check;
+ALTER TABLE public.matches ADD CONSTRAINT matches_status_check
+    CHECK (status IN ('active', 'sold_out', 'expired', 'deleted', 'price_changed'));
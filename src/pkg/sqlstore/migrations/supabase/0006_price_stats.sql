@@ -0,0 +1,16 @@
+-- Resumo do histórico de preços de uma oferta, calculado no banco: o bot
+-- chama por RPC em vez de baixar todos os preços. Sem offer_id, agrupa por
+-- produto e loja.
+
+CREATE FUNCTION public.price_stats(p_offer_id text, p_product_id text, p_store text)
+RETURNS TABLE (total bigint, lowest double precision, total_30d bigint, average_30d double precision)
+LANGUAGE sql STABLE
+AS $$
+    SELECT count(*),
+           min(price),
+           count(*) FILTER (WHERE recorded_at >= now() - interval '30 days'),
+           avg(price) FILTER (WHERE recorded_at >= now() - interval '30 days')
+    FROM public.price_history
+    WHERE CASE WHEN p_offer_id <> '' THEN offer_id = p_offer_id
+               ELSE product_id = p_product_id AND store = p_store END;
+$$;
@@ -0,0 +1,4 @@
+-- Conta que leu o post: em grupos comuns os IDs das mensagens são de cada
+-- conta, e o bot confere edições e remoções com a mesma conta.
+
+ALTER TABLE public.matches ADD COLUMN account text NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+package sqlstore
+
+import _ "github.com/jackc/pgx/v5/stdlib"
@@ -0,0 +1,49 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"os"
+	"testing"
+)
+
+// TestPostgres roda contra o banco de DB_TEST_DSN (veja make postgres), num
+// schema próprio recriado a cada execução.
+func TestPostgres(t *testing.T) {
+	dsn := os.Getenv("DB_TEST_DSN")
+	if dsn == "" {
+		t.Skip("DB_TEST_DSN not set")
+	}
+	ctx := context.Background()
+
+	db, err := sql.Open(Postgres.Driver, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, stmt := range []string{
+		`DROP SCHEMA IF EXISTS sqlstore_test CASCADE`,
+		`CREATE SCHEMA sqlstore_test`,
+	} {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	query.Set("search_path", "sqlstore_test")
+	u.RawQuery = query.Encode()
+
+	r, err := Open(ctx, Postgres, u.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	testRepository(t, r)
+}
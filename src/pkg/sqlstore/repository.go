@@ -0,0 +1,269 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"bot-telegram/src/internal/domain"
+
+	"github.com/go-faster/errors"
+)
+
+var _ domain.Repository = (*Repository)(nil)
+
+func (r *Repository) ListSessions(ctx context.Context) ([]domain.Session, error) {
+	rows, err := r.query(ctx, `SELECT id, user_id, cron_schedule, folder, provider_ids, product_ids, stores,
+		watch_coupons, created_at, updated_at FROM sessions ORDER BY id`)
+	if err != nil {
+		return nil, errors.Wrap(err, "[SQL] list sessions")
+	}
+	defer rows.Close()
+
+	var sessions []domain.Session
+	for rows.Next() {
+		var (
+			s                           domain.Session
+			providers, products, stores []byte
+		)
+		if err := rows.Scan(&s.SessionId, &s.OwnerID, &s.CronSchedule, &s.Folder, &providers, &products, &stores,
+			&s.WatchCoupons, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, errors.Wrap(err, "[SQL] list sessions")
+		}
+		if err := unmarshal(providers, &s.ProviderIds, products, &s.ProductIds, stores, &s.Stores); err != nil {
+			return nil, errors.Wrapf(err, "[SQL] session %s", s.SessionId)
+		}
+		sessions = append(sessions, s)
+	}
+
+	return sessions, wrap(rows.Err(), "[SQL] list sessions")
+}
+
+// ListProducts returns the products of owner; without an owner, none.
+func (r *Repository) ListProducts(ctx context.Context, owner string, ids []string) ([]domain.Product, error) {
+	if owner == "" {
+		return nil, nil
+	}
+
+	rows, err := r.query(ctx, `SELECT id, user_id, title, name, created_at, updated_at FROM products
+		WHERE user_id = ? ORDER BY id`, owner)
+	if err != nil {
+		return nil, errors.Wrap(err, "[SQL] list products")
+	}
+	defer rows.Close()
+
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	var products []domain.Product
+	for rows.Next() {
+		var p domain.Product
+		if err := rows.Scan(&p.ProductID, &p.OwnerID, &p.Title, &p.Name, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, errors.Wrap(err, "[SQL] list products")
+		}
+		if len(wanted) == 0 || wanted[p.ProductID] {
+			products = append(products, p)
+		}
+	}
+
+	return products, wrap(rows.Err(), "[SQL] list products")
+}
+
+func (r *Repository) SaveMatch(ctx context.Context, m domain.Match) error {
+	links, err := json.Marshal(orEmpty(m.Links))
+	if err != nil {
+		return err
+	}
+	coupons, err := json.Marshal(orEmpty(m.Coupons))
+	if err != nil {
+		return err
+	}
+
+	status := m.Status
+	if status == "" {
+		status = domain.MatchActive
+	}
+
+	err = r.exec(ctx, `INSERT INTO matches (session_id, user_id, product_id, product_name, source_id, account, message_id,
+		text, links, store, offer_id, coupons, price, historical_low, below_average, posted_at, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		m.SessionID, m.OwnerID, m.ProductID, m.ProductName, m.SourceID, m.Account, m.MessageID,
+		m.Text, string(links), m.Store, m.OfferID, string(coupons), m.Price, m.HistoricalLow, m.BelowAverage, m.PostedAt, status)
+	return wrap(err, "[SQL] save match")
+}
+
+func (r *Repository) ListTrackedMatches(ctx context.Context, since string) ([]domain.Match, error) {
+	rows, err := r.query(ctx, `SELECT session_id, user_id, product_id, product_name, source_id, account, message_id,
+		text, links, store, offer_id, coupons, price, historical_low, below_average, posted_at,
+		status, previous_price, edited_at
+		FROM matches WHERE posted_at >= ? AND status IN ('active', 'price_changed')
+		ORDER BY source_id, message_id`, since)
+	if err != nil {
+		return nil, errors.Wrap(err, "[SQL] list tracked matches")
+	}
+	defer rows.Close()
+
+	var matches []domain.Match
+	for rows.Next() {
+		var (
+			m              domain.Match
+			links, coupons []byte
+			editedAt       sql.NullString
+		)
+		if err := rows.Scan(&m.SessionID, &m.OwnerID, &m.ProductID, &m.ProductName, &m.SourceID, &m.Account, &m.MessageID,
+			&m.Text, &links, &m.Store, &m.OfferID, &coupons, &m.Price, &m.HistoricalLow, &m.BelowAverage, &m.PostedAt,
+			&m.Status, &m.PreviousPrice, &editedAt); err != nil {
+			return nil, errors.Wrap(err, "[SQL] list tracked matches")
+		}
+		m.EditedAt = editedAt.String
+		if err := unmarshal(links, &m.Links, coupons, &m.Coupons); err != nil {
+			return nil, errors.Wrapf(err, "[SQL] match %d/%d", m.SourceID, m.MessageID)
+		}
+		matches = append(matches, m)
+	}
+
+	return matches, wrap(rows.Err(), "[SQL] list tracked matches")
+}
+
+func (r *Repository) UpdateMatch(ctx context.Context, m domain.Match) error {
+	err := r.exec(ctx, `UPDATE matches SET status = ?, text = ?, price = ?, previous_price = ?, edited_at = ?
+		WHERE session_id = ? AND product_id = ? AND source_id = ? AND account = ? AND message_id = ?`,
+		m.Status, m.Text, m.Price, m.PreviousPrice, sql.NullString{String: m.EditedAt, Valid: m.EditedAt != ""},
+		m.SessionID, m.ProductID, m.SourceID, m.Account, m.MessageID)
+	return wrap(err, "[SQL] update match")
+}
+
+func (r *Repository) ListChannels(ctx context.Context, owner string) ([]domain.NotificationChannel, error) {
+	rows, err := r.query(ctx, `SELECT id, user_id, kind, target, enabled FROM notification_channels
+		WHERE user_id = ? AND enabled ORDER BY id`, owner)
+	if err != nil {
+		return nil, errors.Wrap(err, "[SQL] list notification channels")
+	}
+	defer rows.Close()
+
+	var channels []domain.NotificationChannel
+	for rows.Next() {
+		var c domain.NotificationChannel
+		if err := rows.Scan(&c.ID, &c.OwnerID, &c.Kind, &c.Target, &c.Enabled); err != nil {
+			return nil, errors.Wrap(err, "[SQL] list notification channels")
+		}
+		channels = append(channels, c)
+	}
+
+	return channels, wrap(rows.Err(), "[SQL] list notification channels")
+}
+
+func (r *Repository) UpsertProviders(ctx context.Context, providers []domain.Provider) error {
+	for _, p := range providers {
+		err := r.exec(ctx, `INSERT INTO providers (id, kind, title, username, description,
+			participants_count, broadcast, megagroup, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET kind = excluded.kind, title = excluded.title,
+				username = excluded.username, description = excluded.description,
+				participants_count = excluded.participants_count, broadcast = excluded.broadcast,
+				megagroup = excluded.megagroup, updated_at = excluded.updated_at`,
+			p.ProviderID, p.Kind, p.Title, p.Username, p.Description,
+			p.ParticipantsCount, p.Broadcast, p.Megagroup, p.UpdatedAt)
+		if err != nil {
+			return errors.Wrapf(err, "[SQL] upsert provider %s", p.ProviderID)
+		}
+	}
+	return nil
+}
+
+func (r *Repository) RecordPrice(ctx context.Context, p domain.PricePoint) error {
+	err := r.exec(ctx, `INSERT INTO price_history (product_id, offer_id, store, price, source_id,
+		message_id, recorded_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		p.ProductID, p.OfferID, p.Store, p.Price, p.SourceID, p.MessageID, p.RecordedAt)
+	return wrap(err, "[SQL] record price")
+}
+
+func (r *Repository) PriceStats(ctx context.Context, p domain.PricePoint) (domain.PriceStats, error) {
+	var (
+		stats  domain.PriceStats
+		filter = `offer_id = ?`
+		args   = []any{p.OfferID}
+	)
+	if p.OfferID == "" {
+		filter, args = `product_id = ? AND store = ?`, []any{p.ProductID, p.Store}
+	}
+
+	var lowest sql.NullFloat64
+	err := r.db.QueryRowContext(ctx, r.rebind(`SELECT COUNT(*), MIN(price) FROM price_history WHERE `+filter), args...).
+		Scan(&stats.Count, &lowest)
+	if err != nil {
+		return stats, errors.Wrap(err, "[SQL] price stats")
+	}
+	stats.Lowest = lowest.Float64
+
+	since := time.Now().AddDate(0, 0, -30).UTC().Format(time.RFC3339)
+	var average sql.NullFloat64
+	err = r.db.QueryRowContext(ctx, r.rebind(`SELECT COUNT(*), AVG(price) FROM price_history WHERE `+filter+` AND recorded_at >= ?`), append(args, since)...).
+		Scan(&stats.Count30, &average)
+	if err != nil {
+		return stats, errors.Wrap(err, "[SQL] price stats")
+	}
+	stats.Average30 = average.Float64
+
+	return stats, nil
+}
+
+func (r *Repository) SaveRun(ctx context.Context, run domain.Run) error {
+	err := r.exec(ctx, `INSERT INTO runs (started_at, finished_at, matches, error) VALUES (?, ?, ?, ?)`,
+		run.StartedAt, run.FinishedAt, run.Matches, run.Error)
+	return wrap(err, "[SQL] save run")
+}
+
+func (r *Repository) GetState(ctx context.Context, key string) ([]byte, error) {
+	var value []byte
+	err := r.db.QueryRowContext(ctx, r.rebind(`SELECT value FROM bot_state WHERE key = ?`), key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "[SQL] get state")
+	}
+	return value, nil
+}
+
+func (r *Repository) SetState(ctx context.Context, key string, value []byte) error {
+	err := r.exec(ctx, `INSERT INTO bot_state (key, value) VALUES (?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value`, key, value)
+	return wrap(err, "[SQL] set state")
+}
+
+// wrap is errors.Wrap that keeps nil as nil.
+func wrap(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	return errors.Wrap(err, message)
+}
+
+// unmarshal decodifica pares (coluna JSON, destino); coluna vazia é ignorada.
+func unmarshal(pairs ...any) error {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		data := pairs[i].([]byte)
+		if len(data) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(data, pairs[i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func orEmpty[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
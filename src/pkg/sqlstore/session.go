@@ -0,0 +1,41 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/session"
+)
+
+// SessionStorage keeps the Telegram MTProto session of an account in the
+// telegram_sessions table, like supabase.SessionStorage. Wrap it with
+// telegram.NewEncryptedSessionStorage: the session grants full account access.
+type SessionStorage struct {
+	repo *Repository
+	id   string
+}
+
+// SessionStorage returns the storage of the session id, e.g. the phone.
+func (r *Repository) SessionStorage(id string) *SessionStorage {
+	return &SessionStorage{repo: r, id: id}
+}
+
+func (s *SessionStorage) LoadSession(ctx context.Context) ([]byte, error) {
+	var data []byte
+	err := s.repo.db.QueryRowContext(ctx, s.repo.rebind(`SELECT data FROM telegram_sessions WHERE id = ?`), s.id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, session.ErrNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "[SQL] load telegram session")
+	}
+	return data, nil
+}
+
+func (s *SessionStorage) StoreSession(ctx context.Context, data []byte) error {
+	err := s.repo.exec(ctx, `INSERT INTO telegram_sessions (id, data, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at`,
+		s.id, data, now())
+	return wrap(err, "[SQL] store telegram session")
+}
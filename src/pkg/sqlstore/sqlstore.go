@@ -0,0 +1,132 @@
+// Package sqlstore implementa os repositórios sobre database/sql, com as
+// migrações de cada banco embutidas no binário.
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/go-faster/errors"
+)
+
+// Dialect is a database the repository can run on.
+type Dialect struct {
+	// Driver is the database/sql driver name.
+	Driver string
+	// migrations is the directory of the SQL files in the embedded FS.
+	migrations string
+	// numbered placeholders ($1, $2, ...) instead of ?.
+	numbered bool
+	// setup runs on every new database handle.
+	setup []string
+	// migrationsSetup runs after schema_migrations is created.
+	migrationsSetup []string
+	// maxConns limits the open connections; 0 is unlimited.
+	maxConns int
+}
+
+// SQLite serializes the writes through one connection: the pipeline stages
+// write concurrently and SQLite has a single writer.
+var SQLite = Dialect{
+	Driver:     "sqlite",
+	migrations: "migrations/sqlite",
+	setup:      []string{"PRAGMA journal_mode = WAL", "PRAGMA busy_timeout = 5000"},
+	maxConns:   1,
+}
+
+// Postgres connects with pgx; dsn is a postgres:// URL.
+var Postgres = Dialect{
+	Driver:     "pgx",
+	migrations: "migrations/postgres",
+	numbered:   true,
+}
+
+// Repository implements domain.Repository on a SQL database.
+type Repository struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// Supabase are the tables the supabase package reads through PostgREST,
+// with their row level security policies. It is only used by Migrate: dsn is
+// the connection string of the project database.
+var Supabase = Dialect{
+	Driver:     "pgx",
+	migrations: "migrations/supabase",
+	numbered:   true,
+	// O PostgREST expõe o schema public; sem políticas, só o dono da
+	// tabela (o migrate) a enxerga.
+	migrationsSetup: []string{"ALTER TABLE schema_migrations ENABLE ROW LEVEL SECURITY"},
+}
+
+// Open connects to dsn, applies the pending migrations and returns the
+// repository.
+func Open(ctx context.Context, dialect Dialect, dsn string) (*Repository, error) {
+	r, err := connect(ctx, dialect, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.Migrate(ctx); err != nil {
+		r.Close()
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func connect(ctx context.Context, dialect Dialect, dsn string) (*Repository, error) {
+	db, err := sql.Open(dialect.Driver, dsn)
+	if err != nil {
+		return nil, errors.Wrapf(err, "[SQL] open %s", dialect.Driver)
+	}
+	if dialect.maxConns > 0 {
+		db.SetMaxOpenConns(dialect.maxConns)
+	}
+
+	for _, stmt := range dialect.setup {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			db.Close()
+			return nil, errors.Wrapf(err, "[SQL] %s", stmt)
+		}
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "[SQL] ping")
+	}
+
+	return &Repository{db: db, dialect: dialect}, nil
+}
+
+func (r *Repository) Close() error {
+	return r.db.Close()
+}
+
+// rebind troca os ? pelos placeholders do dialeto.
+func (r *Repository) rebind(query string) string {
+	if !r.dialect.numbered {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+func (r *Repository) exec(ctx context.Context, query string, args ...any) error {
+	_, err := r.db.ExecContext(ctx, r.rebind(query), args...)
+	return err
+}
+
+func (r *Repository) query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return r.db.QueryContext(ctx, r.rebind(query), args...)
+}
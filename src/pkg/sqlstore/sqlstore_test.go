@@ -0,0 +1,173 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bot-telegram/src/internal/domain"
+
+	"github.com/gotd/td/session"
+)
+
+func TestSQLite(t *testing.T) {
+	ctx := context.Background()
+
+	r, err := Open(ctx, SQLite, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	testRepository(t, r)
+}
+
+// testRepository exercita os métodos do repositório num banco recém-migrado.
+func testRepository(t *testing.T, r *Repository) {
+	ctx := context.Background()
+
+	applied, err := r.Migrate(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("second Migrate applied %v", applied)
+	}
+
+	for _, stmt := range []string{
+		`INSERT INTO sessions (id, user_id, product_ids) VALUES ('s1', 'alice', '["ssd"]')`,
+		`INSERT INTO products (id, user_id, name) VALUES ('ssd', 'alice', 'ssd')`,
+		`INSERT INTO products (id, user_id, name) VALUES ('tv', 'bob', 'tv')`,
+		`INSERT INTO notification_channels (id, user_id, kind, target) VALUES ('c1', 'alice', 'webhook', 'http://x')`,
+	} {
+		if err := r.exec(ctx, stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sessions, err := r.ListSessions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].OwnerID != "alice" || len(sessions[0].ProductIds) != 1 {
+		t.Errorf("ListSessions = %+v", sessions)
+	}
+
+	products, err := r.ListProducts(ctx, "alice", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(products) != 1 || products[0].ProductID != "ssd" {
+		t.Errorf("ListProducts(alice) = %+v", products)
+	}
+	if products, err := r.ListProducts(ctx, "", nil); err != nil || len(products) != 0 {
+		t.Errorf("ListProducts(\"\") = %+v, %v; want no products", products, err)
+	}
+
+	channels, err := r.ListChannels(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(channels) != 1 || channels[0].Kind != domain.ChannelWebhook {
+		t.Errorf("ListChannels(alice) = %+v", channels)
+	}
+
+	now := time.Now().UTC()
+	match := domain.Match{
+		SessionID: "s1",
+		OwnerID:   "alice",
+		ProductID: "ssd",
+		SourceID:  10,
+		Account:   "+5511",
+		MessageID: 20,
+		Text:      "SSD 1TB R$ 300",
+		Price:     300,
+		PostedAt:  now.Format(time.RFC3339),
+	}
+	if err := r.SaveMatch(ctx, match); err != nil {
+		t.Fatal(err)
+	}
+
+	tracked, err := r.ListTrackedMatches(ctx, now.Add(-time.Hour).Format(time.RFC3339))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracked) != 1 || tracked[0].Status != domain.MatchActive || tracked[0].Account != "+5511" || tracked[0].EditedAt != "" {
+		t.Fatalf("ListTrackedMatches = %+v", tracked)
+	}
+
+	edited := now.Add(time.Minute).Truncate(time.Second)
+	match.Status = domain.MatchPriceChanged
+	match.PreviousPrice, match.Price = 300, 280
+	match.EditedAt = edited.Format(time.RFC3339)
+	if err := r.UpdateMatch(ctx, match); err != nil {
+		t.Fatal(err)
+	}
+	tracked, err = r.ListTrackedMatches(ctx, now.Add(-time.Hour).Format(time.RFC3339))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracked) != 1 || tracked[0].Price != 280 {
+		t.Fatalf("ListTrackedMatches = %+v", tracked)
+	}
+	if at, err := time.Parse(time.RFC3339, tracked[0].EditedAt); err != nil || !at.Equal(edited) {
+		t.Errorf("EditedAt = %q, want %s", tracked[0].EditedAt, match.EditedAt)
+	}
+
+	match.Status = domain.MatchSoldOut
+	if err := r.UpdateMatch(ctx, match); err != nil {
+		t.Fatal(err)
+	}
+	tracked, err = r.ListTrackedMatches(ctx, now.Add(-time.Hour).Format(time.RFC3339))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracked) != 0 {
+		t.Errorf("sold out match still tracked: %+v", tracked)
+	}
+
+	for _, price := range []float64{300, 250} {
+		point := domain.PricePoint{ProductID: "ssd", OfferID: "amazon:X", Price: price, RecordedAt: now.Format(time.RFC3339)}
+		if err := r.RecordPrice(ctx, point); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stats, err := r.PriceStats(ctx, domain.PricePoint{ProductID: "ssd", OfferID: "amazon:X"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Count != 2 || stats.Lowest != 250 || stats.Count30 != 2 || stats.Average30 != 275 {
+		t.Errorf("PriceStats = %+v", stats)
+	}
+
+	if _, err := r.GetState(ctx, "missing"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("GetState(missing) error = %v, want ErrNotFound", err)
+	}
+	for _, value := range []string{"1", "2"} {
+		if err := r.SetState(ctx, "cursor", []byte(value)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if value, err := r.GetState(ctx, "cursor"); err != nil || string(value) != "2" {
+		t.Errorf("GetState(cursor) = %q, %v", value, err)
+	}
+
+	if err := r.SaveRun(ctx, domain.Run{StartedAt: now.Format(time.RFC3339), FinishedAt: now.Format(time.RFC3339)}); err != nil {
+		t.Fatal(err)
+	}
+
+	storage := r.SessionStorage("+5511")
+	if _, err := storage.LoadSession(ctx); !errors.Is(err, session.ErrNotFound) {
+		t.Errorf("LoadSession(new) error = %v, want session.ErrNotFound", err)
+	}
+	for _, data := range []string{"first", "second"} {
+		if err := storage.StoreSession(ctx, []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if data, err := storage.LoadSession(ctx); err != nil || string(data) != "second" {
+		t.Errorf("LoadSession() = %q, %v", data, err)
+	}
+}
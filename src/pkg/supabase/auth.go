@@ -0,0 +1,278 @@
+package supabase
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"bot-telegram/src/pkg/config"
+
+	"github.com/go-faster/errors"
+	"github.com/supabase-community/gotrue-go/types"
+	"github.com/supabase-community/supabase-go"
+)
+
+// Modos de autenticação (SUPABASE_AUTH_MODE).
+const (
+	// AuthPassword signs in as a user with email and password.
+	AuthPassword = "password"
+	// AuthServiceRole uses the service role key, which bypasses RLS.
+	AuthServiceRole = "service_role"
+	// AuthJWT uses a pre-issued access token, refreshed when a refresh token
+	// is given.
+	AuthJWT = "jwt"
+)
+
+type Config struct {
+	URL string
+	// Key is the anon key; the service role key in AuthServiceRole mode.
+	Key  string
+	Mode string
+
+	User     string
+	Password string
+
+	ServiceRoleKey string
+
+	JWT          string
+	RefreshToken string
+}
+
+// ConfigFromEnv reads SUPABASE_URL, SUPABASE_KEY and SUPABASE_AUTH_MODE plus
+// the credentials of the mode: SUPABASE_USER and SUPABASE_PASSWORD,
+// SUPABASE_SERVICE_ROLE_KEY, or SUPABASE_JWT and SUPABASE_REFRESH_TOKEN.
+// Secrets can also be read from NAME_FILE.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		URL:  os.Getenv("SUPABASE_URL"),
+		Key:  os.Getenv("SUPABASE_KEY"),
+		Mode: os.Getenv("SUPABASE_AUTH_MODE"),
+		User: os.Getenv("SUPABASE_USER"),
+	}
+	if cfg.Mode == "" {
+		cfg.Mode = AuthPassword
+	}
+
+	for name, dst := range map[string]*string{
+		"SUPABASE_PASSWORD":         &cfg.Password,
+		"SUPABASE_SERVICE_ROLE_KEY": &cfg.ServiceRoleKey,
+		"SUPABASE_JWT":              &cfg.JWT,
+		"SUPABASE_REFRESH_TOKEN":    &cfg.RefreshToken,
+	} {
+		value, err := config.Secret(name)
+		if err != nil {
+			return cfg, errors.Wrap(err, "[SUPABASE]")
+		}
+		*dst = value
+	}
+
+	return cfg, nil
+}
+
+// Validate reports every setting the mode needs that is missing.
+func (c Config) Validate() error {
+	var missing []string
+	require := func(value, name string) {
+		if value == "" {
+			missing = append(missing, name)
+		}
+	}
+
+	require(c.URL, "SUPABASE_URL")
+	switch c.Mode {
+	case AuthPassword:
+		require(c.Key, "SUPABASE_KEY")
+		require(c.User, "SUPABASE_USER")
+		require(c.Password, "SUPABASE_PASSWORD")
+	case AuthServiceRole:
+		require(c.ServiceRoleKey, "SUPABASE_SERVICE_ROLE_KEY")
+	case AuthJWT:
+		require(c.Key, "SUPABASE_KEY")
+		require(c.JWT, "SUPABASE_JWT")
+	default:
+		return errors.Errorf("[SUPABASE] unknown SUPABASE_AUTH_MODE %q (use %s, %s or %s)", c.Mode, AuthPassword, AuthServiceRole, AuthJWT)
+	}
+
+	if len(missing) > 0 {
+		return errors.Errorf("[SUPABASE] auth mode %s is missing %s", c.Mode, strings.Join(missing, ", "))
+	}
+
+	if c.Mode == AuthJWT && c.RefreshToken == "" {
+		if exp, ok := jwtExpiry(c.JWT); ok && time.Now().After(exp) {
+			return errors.Errorf("[SUPABASE] SUPABASE_JWT expired at %s and there is no SUPABASE_REFRESH_TOKEN", exp.Format(time.RFC3339))
+		}
+	}
+
+	return nil
+}
+
+// NewClient creates a client from the environment (see ConfigFromEnv); the
+// token is refreshed until ctx is done.
+func NewClient(ctx context.Context) (*supabase.Client, error) {
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClientWithConfig(ctx, cfg)
+}
+
+// NewClientWithConfig validates cfg and authenticates the client. In the
+// password and jwt modes the access token is refreshed in the background
+// until ctx is done.
+func NewClientWithConfig(ctx context.Context, cfg Config) (*supabase.Client, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	key := cfg.Key
+	if cfg.Mode == AuthServiceRole {
+		key = cfg.ServiceRoleKey
+	}
+	client, err := supabase.NewClient(cfg.URL, key, &supabase.ClientOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "[SUPABASE] Failed to initalize the client: ")
+	}
+
+	creds := &clientCredentials{url: cfg.URL, key: key, token: key}
+	credentials.Store(client, creds)
+
+	switch cfg.Mode {
+	case AuthPassword:
+		session, err := retry(ctx, "sign in", func() (types.Session, error) {
+			return client.SignInWithEmailPassword(cfg.User, cfg.Password)
+		})
+		if err != nil {
+			return nil, err
+		}
+		creds.setToken(session.AccessToken)
+		go keepFresh(ctx, client, cfg, session)
+
+	case AuthJWT:
+		session := types.Session{AccessToken: cfg.JWT, RefreshToken: cfg.RefreshToken}
+		if exp, ok := jwtExpiry(cfg.JWT); ok {
+			session.ExpiresAt = exp.Unix()
+		}
+		client.UpdateAuthSession(session)
+		creds.setToken(cfg.JWT)
+		if cfg.RefreshToken != "" {
+			go keepFresh(ctx, client, cfg, session)
+		}
+	}
+
+	return client, nil
+}
+
+// keepFresh refreshes the access token before it expires. Failures are
+// logged and retried with backoff; in password mode, a refresh token that no
+// longer works is replaced by signing in again.
+func keepFresh(ctx context.Context, client *supabase.Client, cfg Config, session types.Session) {
+	for {
+		expiresAt := time.Unix(session.ExpiresAt, 0)
+		if session.ExpiresAt == 0 {
+			expiresAt = time.Now().Add(time.Duration(session.ExpiresIn) * time.Second)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(expiresAt) * 3 / 4):
+		}
+
+		next, err := retry(ctx, "refresh token", func() (types.Session, error) {
+			return client.RefreshToken(session.RefreshToken)
+		})
+		if err != nil && cfg.Mode == AuthPassword && ctx.Err() == nil {
+			log.Printf("%v; signing in again", err)
+			next, err = retry(ctx, "sign in", func() (types.Session, error) {
+				return client.SignInWithEmailPassword(cfg.User, cfg.Password)
+			})
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			// Sem token novo as consultas vão falhar com 401; tenta de novo em
+			// 30s em vez de desistir.
+			log.Printf("%v; requests will fail until it succeeds", err)
+			session.ExpiresAt = time.Now().Add(40 * time.Second).Unix()
+			continue
+		}
+
+		session = next
+		if creds, ok := credentials.Load(client); ok {
+			creds.(*clientCredentials).setToken(session.AccessToken)
+		}
+	}
+}
+
+// credentials keeps the URL, key and current access token of the clients
+// created by NewClientWithConfig, for the Realtime connection.
+var credentials sync.Map // *supabase.Client → *clientCredentials
+
+type clientCredentials struct {
+	url, key string
+
+	mu    sync.Mutex
+	token string
+}
+
+func (c *clientCredentials) setToken(token string) {
+	c.mu.Lock()
+	c.token = token
+	c.mu.Unlock()
+}
+
+func (c *clientCredentials) accessToken() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// retry calls f up to 4 times, backing off 1s, 2s and 4s.
+func retry(ctx context.Context, what string, f func() (types.Session, error)) (types.Session, error) {
+	var err error
+	for attempt := 0; attempt < 4; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return types.Session{}, ctx.Err()
+			case <-time.After(time.Duration(1<<(attempt-1)) * time.Second):
+			}
+		}
+
+		var session types.Session
+		if session, err = f(); err == nil {
+			return session, nil
+		}
+	}
+
+	return types.Session{}, errors.Wrapf(err, "[SUPABASE] %s failed after 4 attempts", what)
+}
+
+// jwtExpiry reads the exp claim of token without verifying it.
+func jwtExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+
+	return time.Unix(claims.Exp, 0), true
+}
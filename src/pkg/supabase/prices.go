@@ -0,0 +1,62 @@
+package supabase
+
+import (
+	"context"
+	"encoding/json"
+
+	"bot-telegram/src/internal/domain"
+
+	"github.com/go-faster/errors"
+)
+
+// RecordPrice keeps the price of a match in the price_history table.
+func (r Repository) RecordPrice(ctx context.Context, point domain.PricePoint) error {
+	_, _, err := r.Client.From("price_history").Insert(point, false, "", "minimal", "").Execute()
+	if err != nil {
+		return errors.Wrap(err, "[SUPABASE] Failed to record price")
+	}
+
+	return nil
+}
+
+// PriceStats summarizes the prices of the offer of point with the
+// price_stats function (migration 0006_price_stats).
+func (r Repository) PriceStats(ctx context.Context, point domain.PricePoint) (domain.PriceStats, error) {
+	var stats domain.PriceStats
+
+	body := r.Client.Rpc("price_stats", "", map[string]string{
+		"p_offer_id":   point.OfferID,
+		"p_product_id": point.ProductID,
+		"p_store":      point.Store,
+	})
+
+	var rows []struct {
+		Total      int      `json:"total"`
+		Lowest     *float64 `json:"lowest"`
+		Total30d   int      `json:"total_30d"`
+		Average30d *float64 `json:"average_30d"`
+	}
+	if err := json.Unmarshal([]byte(body), &rows); err != nil {
+		// O PostgREST responde os erros com um objeto, não uma lista.
+		var failure struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal([]byte(body), &failure) == nil && failure.Message != "" {
+			return stats, errors.Errorf("[SUPABASE] Failed to get price stats: %s", failure.Message)
+		}
+		return stats, errors.Wrap(err, "[SUPABASE] Failed to get price stats")
+	}
+	if len(rows) == 0 {
+		return stats, nil
+	}
+
+	stats.Count, stats.Count30 = rows[0].Total, rows[0].Total30d
+	if rows[0].Lowest != nil {
+		stats.Lowest = *rows[0].Lowest
+	}
+	if rows[0].Average30d != nil {
+		stats.Average30 = *rows[0].Average30d
+	}
+
+	return stats, nil
+}
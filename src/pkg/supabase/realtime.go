@@ -0,0 +1,247 @@
+package supabase
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/go-faster/errors"
+	"github.com/supabase-community/supabase-go"
+)
+
+// Change is a row inserted, updated or deleted in a table.
+type Change struct {
+	Table string `json:"table"`
+	// Type is INSERT, UPDATE, DELETE or ChangeResync.
+	Type      string          `json:"type"`
+	Record    json.RawMessage `json:"record"`
+	OldRecord json.RawMessage `json:"old_record"`
+}
+
+// Realtime listens to Postgres changes through Supabase Realtime. The tables
+// must be in the supabase_realtime publication (see the migrations) and the
+// user needs SELECT on them: RLS also applies to the changes.
+type Realtime struct {
+	// URL is the websocket endpoint, e.g.
+	// wss://<projeto>.supabase.co/realtime/v1/websocket.
+	URL    string
+	APIKey string
+	// Token returns the current access token; optional, defaults to APIKey.
+	Token func() string
+	// Heartbeat defaults to 25s.
+	Heartbeat time.Duration
+}
+
+// NewRealtime creates a Realtime for a client created by NewClientWithConfig,
+// following its access token as it is refreshed.
+func NewRealtime(client *supabase.Client) (*Realtime, error) {
+	value, ok := credentials.Load(client)
+	if !ok {
+		return nil, errors.New("[SUPABASE] realtime needs a client created by NewClientWithConfig")
+	}
+	creds := value.(*clientCredentials)
+
+	u, err := url.Parse(strings.TrimRight(creds.url, "/") + "/realtime/v1/websocket")
+	if err != nil {
+		return nil, errors.Wrap(err, "[SUPABASE] invalid SUPABASE_URL")
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	}
+
+	return &Realtime{URL: u.String(), APIKey: creds.key, Token: creds.accessToken}, nil
+}
+
+// ChangeResync is the Type of the change Listen sends, with no table, after
+// it joins again: the changes made while disconnected are lost, so the
+// listener should reload everything.
+const ChangeResync = "RESYNC"
+
+type phoenixMessage struct {
+	Topic   string          `json:"topic"`
+	Event   string          `json:"event"`
+	Payload json.RawMessage `json:"payload"`
+	Ref     string          `json:"ref,omitempty"`
+	JoinRef string          `json:"join_ref,omitempty"`
+}
+
+// Listen calls fn for each change in tables until ctx is done, reconnecting
+// with backoff when the connection drops; after each reconnect fn receives a
+// ChangeResync. fn runs in the read loop, so it should not block for long.
+func (r *Realtime) Listen(ctx context.Context, tables []string, fn func(Change)) error {
+	backoff := time.Second
+	joined := false
+	onJoin := func() {
+		if joined {
+			fn(Change{Type: ChangeResync})
+		}
+		joined = true
+	}
+	for {
+		started := time.Now()
+		err := r.listen(ctx, tables, fn, onJoin)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if time.Since(started) > time.Minute {
+			backoff = time.Second
+		}
+		log.Printf("[SUPABASE] realtime: %v; reconnecting in %s", err, backoff)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, time.Minute)
+	}
+}
+
+func (r *Realtime) listen(ctx context.Context, tables []string, fn func(Change), onJoin func()) error {
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return errors.Wrap(err, "invalid realtime url")
+	}
+	query := u.Query()
+	query.Set("apikey", r.APIKey)
+	query.Set("vsn", "1.0.0")
+	u.RawQuery = query.Encode()
+
+	conn, _, err := websocket.Dial(ctx, u.String(), nil)
+	if err != nil {
+		// A URL tem a apikey.
+		return errors.New(strings.ReplaceAll(err.Error(), url.QueryEscape(r.APIKey), "***"))
+	}
+	defer conn.CloseNow()
+	conn.SetReadLimit(1 << 20)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ref := 0
+	send := func(topic, event string, payload any) error {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		ref++
+		return wsjson.Write(ctx, conn, phoenixMessage{
+			Topic:   topic,
+			Event:   event,
+			Payload: data,
+			Ref:     strconv.Itoa(ref),
+			JoinRef: "1",
+		})
+	}
+
+	const topic = "realtime:bot"
+	changes := make([]map[string]string, len(tables))
+	for i, table := range tables {
+		changes[i] = map[string]string{"event": "*", "schema": "public", "table": table}
+	}
+	token := r.token()
+	joinRef := strconv.Itoa(ref + 1)
+	if err := send(topic, "phx_join", map[string]any{
+		"config":       map[string]any{"postgres_changes": changes},
+		"access_token": token,
+	}); err != nil {
+		return errors.Wrap(err, "join")
+	}
+
+	// O canal fecha se o servidor não receber heartbeats; o token renovado
+	// vai junto.
+	heartbeat := r.Heartbeat
+	if heartbeat <= 0 {
+		heartbeat = 25 * time.Second
+	}
+	writeErr := make(chan error, 1)
+	go func() {
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			err := send("phoenix", "heartbeat", struct{}{})
+			if next := r.token(); err == nil && next != token {
+				token = next
+				err = send(topic, "access_token", map[string]string{"access_token": token})
+			}
+			if err != nil {
+				writeErr <- err
+				cancel()
+				return
+			}
+		}
+	}()
+
+	for {
+		var msg phoenixMessage
+		if err := wsjson.Read(ctx, conn, &msg); err != nil {
+			select {
+			case err := <-writeErr:
+				return errors.Wrap(err, "write")
+			default:
+			}
+			return errors.Wrap(err, "read")
+		}
+
+		switch msg.Event {
+		case "postgres_changes":
+			var payload struct {
+				Data Change `json:"data"`
+			}
+			if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+				log.Printf("[SUPABASE] realtime: invalid change: %v", err)
+				continue
+			}
+			fn(payload.Data)
+
+		case "phx_reply":
+			var reply struct {
+				Status   string          `json:"status"`
+				Response json.RawMessage `json:"response"`
+			}
+			if err := json.Unmarshal(msg.Payload, &reply); err == nil && reply.Status != "ok" {
+				return errors.Errorf("%s: %s %s", msg.Topic, reply.Status, reply.Response)
+			}
+			if msg.Topic == topic && msg.Ref == joinRef {
+				onJoin()
+			}
+
+		case "system":
+			var status struct {
+				Status  string `json:"status"`
+				Message string `json:"message"`
+			}
+			if err := json.Unmarshal(msg.Payload, &status); err == nil && status.Status == "error" {
+				return errors.Errorf("%s: %s", msg.Topic, status.Message)
+			}
+
+		case "phx_error", "phx_close":
+			return errors.Errorf("%s: %s", msg.Topic, msg.Event)
+		}
+	}
+}
+
+func (r *Realtime) token() string {
+	if r.Token != nil {
+		if token := r.Token(); token != "" {
+			return token
+		}
+	}
+	return r.APIKey
+}
@@ -0,0 +1,138 @@
+package supabase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+)
+
+// TestRealtime roda Listen contra um servidor Phoenix de mentira: entra no
+// canal, manda um heartbeat, recebe uma mudança e, depois que o servidor
+// derruba a conexão, entra de novo e recebe o ChangeResync.
+func TestRealtime(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	serverErr := make(chan error, 2)
+	var n atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := serveRealtime(ctx, w, req, n.Add(1) == 1); err != nil {
+			serverErr <- err
+		}
+	}))
+	defer server.Close()
+
+	realtime := &Realtime{
+		URL:       "ws" + strings.TrimPrefix(server.URL, "http") + "/realtime/v1/websocket",
+		APIKey:    "anon",
+		Token:     func() string { return "jwt" },
+		Heartbeat: 10 * time.Millisecond,
+	}
+
+	changes := make(chan Change, 2)
+	listenCtx, stop := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- realtime.Listen(listenCtx, []string{"sessions", "products"}, func(change Change) {
+			changes <- change
+		})
+	}()
+
+	for _, want := range []Change{{Table: "products", Type: "INSERT"}, {Type: ChangeResync}} {
+		select {
+		case change := <-changes:
+			if change.Table != want.Table || change.Type != want.Type {
+				t.Errorf("change = %s %s, want %s %s", change.Table, change.Type, want.Table, want.Type)
+			}
+		case err := <-serverErr:
+			t.Fatal(err)
+		case <-ctx.Done():
+			t.Fatal("timeout waiting for", want.Type)
+		}
+	}
+	if got := n.Load(); got != 2 {
+		t.Errorf("connections = %d, want 2", got)
+	}
+
+	stop()
+	if err := <-done; err != nil {
+		t.Errorf("Listen() = %v", err)
+	}
+}
+
+// serveRealtime confere o join e responde; na primeira conexão espera um
+// heartbeat, manda uma mudança e fecha. Na segunda, fica aberta.
+func serveRealtime(ctx context.Context, w http.ResponseWriter, req *http.Request, first bool) error {
+	if req.URL.Query().Get("apikey") != "anon" {
+		http.Error(w, "missing apikey", http.StatusUnauthorized)
+		return nil
+	}
+	conn, err := websocket.Accept(w, req, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.CloseNow()
+
+	var join phoenixMessage
+	if err := wsjson.Read(ctx, conn, &join); err != nil {
+		return err
+	}
+	var payload struct {
+		Config struct {
+			PostgresChanges []map[string]string `json:"postgres_changes"`
+		} `json:"config"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(join.Payload, &payload); err != nil {
+		return err
+	}
+	if join.Event != "phx_join" || join.Topic != "realtime:bot" || payload.AccessToken != "jwt" || len(payload.Config.PostgresChanges) != 2 {
+		return fmt.Errorf("unexpected join: %+v", join)
+	}
+	if err := wsjson.Write(ctx, conn, phoenixMessage{
+		Topic:   join.Topic,
+		Event:   "phx_reply",
+		Payload: json.RawMessage(`{"status":"ok","response":{}}`),
+		Ref:     join.Ref,
+	}); err != nil {
+		return err
+	}
+
+	if !first {
+		// Lê os heartbeats até o cliente sair.
+		for {
+			var msg phoenixMessage
+			if err := wsjson.Read(ctx, conn, &msg); err != nil {
+				return nil
+			}
+		}
+	}
+
+	for {
+		var msg phoenixMessage
+		if err := wsjson.Read(ctx, conn, &msg); err != nil {
+			return err
+		}
+		if msg.Topic == "phoenix" && msg.Event == "heartbeat" {
+			break
+		}
+	}
+	if err := wsjson.Write(ctx, conn, phoenixMessage{
+		Topic:   join.Topic,
+		Event:   "postgres_changes",
+		Payload: json.RawMessage(`{"data":{"table":"products","type":"INSERT","record":{"product_id":"ssd"}}}`),
+	}); err != nil {
+		return err
+	}
+
+	return conn.Close(websocket.StatusGoingAway, "restart")
+}
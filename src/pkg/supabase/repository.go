@@ -0,0 +1,132 @@
+package supabase
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+
+	"bot-telegram/src/internal/domain"
+
+	"github.com/go-faster/errors"
+	"github.com/supabase-community/supabase-go"
+)
+
+// Repository implements domain.Repository on the Supabase tables.
+type Repository struct {
+	Client *supabase.Client
+}
+
+var _ domain.Repository = Repository{}
+
+func (r Repository) ListSessions(ctx context.Context) ([]domain.Session, error) {
+	return GetAllSessions(r.Client)
+}
+
+func (r Repository) ListProducts(ctx context.Context, owner string, ids []string) ([]domain.Product, error) {
+	return GetAllProducts(r.Client, &domain.Session{OwnerID: owner, ProductIds: ids})
+}
+
+func (r Repository) ListChannels(ctx context.Context, owner string) ([]domain.NotificationChannel, error) {
+	var channels []domain.NotificationChannel
+
+	_, err := r.Client.From("notification_channels").Select("*", "", false).
+		Eq("user_id", owner).
+		Eq("enabled", "true").
+		ExecuteTo(&channels)
+	if err != nil {
+		return nil, errors.Wrap(err, "[SUPABASE] Failed to list notification channels")
+	}
+
+	return channels, nil
+}
+
+func (r Repository) SaveMatch(ctx context.Context, match domain.Match) error {
+	return SaveMatch(r.Client, match)
+}
+
+func (r Repository) ListTrackedMatches(ctx context.Context, since string) ([]domain.Match, error) {
+	var matches []domain.Match
+
+	_, err := r.Client.From("matches").Select("*", "", false).
+		Gte("posted_at", since).
+		In("status", []string{domain.MatchActive, domain.MatchPriceChanged}).
+		ExecuteTo(&matches)
+	if err != nil {
+		return nil, errors.Wrap(err, "[SUPABASE] Failed to list tracked matches")
+	}
+
+	return matches, nil
+}
+
+func (r Repository) UpdateMatch(ctx context.Context, match domain.Match) error {
+	update := map[string]any{
+		"status":         match.Status,
+		"text":           match.Text,
+		"price":          match.Price,
+		"previous_price": match.PreviousPrice,
+	}
+	if match.EditedAt != "" {
+		update["edited_at"] = match.EditedAt
+	}
+
+	_, _, err := r.Client.From("matches").Update(update, "minimal", "").
+		Eq("session_id", match.SessionID).
+		Eq("product_id", match.ProductID).
+		Eq("source_id", strconv.FormatInt(match.SourceID, 10)).
+		Eq("account", match.Account).
+		Eq("message_id", strconv.Itoa(match.MessageID)).
+		Execute()
+	if err != nil {
+		return errors.Wrap(err, "[SUPABASE] Failed to update match")
+	}
+
+	return nil
+}
+
+func (r Repository) UpsertProviders(ctx context.Context, providers []domain.Provider) error {
+	return UpsertProviders(r.Client, providers)
+}
+
+func (r Repository) SaveRun(ctx context.Context, run domain.Run) error {
+	_, _, err := r.Client.From("runs").Insert(run, false, "", "minimal", "").Execute()
+	if err != nil {
+		return errors.Wrap(err, "[SUPABASE] Failed to save run")
+	}
+
+	return nil
+}
+
+type stateRow struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func (r Repository) GetState(ctx context.Context, key string) ([]byte, error) {
+	var rows []stateRow
+
+	_, err := r.Client.From("bot_state").Select("*", "", false).Eq("key", key).ExecuteTo(&rows)
+	if err != nil {
+		return nil, errors.Wrap(err, "[SUPABASE] Failed to load state")
+	}
+	if len(rows) == 0 {
+		return nil, domain.ErrNotFound
+	}
+
+	value, err := base64.StdEncoding.DecodeString(rows[0].Value)
+	if err != nil {
+		return nil, errors.Wrap(err, "[SUPABASE] Failed to decode state")
+	}
+
+	return value, nil
+}
+
+func (r Repository) SetState(ctx context.Context, key string, value []byte) error {
+	row := stateRow{Key: key, Value: base64.StdEncoding.EncodeToString(value)}
+
+	_, _, err := r.Client.From("bot_state").Upsert(row, "key", "minimal", "").Execute()
+	if err != nil {
+		return errors.Wrap(err, "[SUPABASE] Failed to store state")
+	}
+
+	return nil
+}
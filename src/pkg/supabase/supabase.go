@@ -2,32 +2,14 @@ package supabase
 
 import (
 	"bot-telegram/src/internal/domain"
-	"os"
+	"context"
+	"encoding/base64"
 
 	"github.com/go-faster/errors"
+	"github.com/gotd/td/session"
 	"github.com/supabase-community/supabase-go"
 )
 
-func NewClient() (*supabase.Client, error) {
-	API_URL := os.Getenv("SUPABASE_URL")
-	API_KEY := os.Getenv("SUPABASE_KEY")
-	USER := os.Getenv("SUPABASE_USER")
-	PASSWORD := os.Getenv("SUPABASE_PASSWORD")
-
-	client, err := supabase.NewClient(API_URL, API_KEY, &supabase.ClientOptions{})
-	if err != nil {
-		return nil, errors.Wrap(err, "[SUPABASE] Failed to initalize the client: ")
-	}
-
-	session, err := client.SignInWithEmailPassword(USER, PASSWORD)
-	if err != nil {
-		return nil, errors.Wrap(err, "[SUPABASE] Sign in failed")
-	}
-	client.EnableTokenAutoRefresh(session)
-
-	return client, nil
-}
-
 func GetAllSessions(client *supabase.Client) ([]domain.Session, error) {
 	var sessions []domain.Session
 
@@ -39,13 +21,92 @@ func GetAllSessions(client *supabase.Client) ([]domain.Session, error) {
 	return sessions, nil
 }
 
+// GetAllProducts returns the products of the session owner; a session
+// without owner has none.
 func GetAllProducts(client *supabase.Client, session *domain.Session) ([]domain.Product, error) {
 	var products []domain.Product
+	if session == nil || session.OwnerID == "" {
+		return products, nil
+	}
+
+	query := client.From("products").Select("*", "id", false).Eq("user_id", session.OwnerID)
+	if len(session.ProductIds) > 0 {
+		query = query.In("id", session.ProductIds)
+	}
 
-	_, err := client.From("products").Select("*", "id", false).ExecuteTo(&products)
+	_, err := query.ExecuteTo(&products)
 	if err != nil {
 		return nil, err
 	}
 
 	return products, nil
 }
+
+func SaveMatch(client *supabase.Client, match domain.Match) error {
+	// links e coupons são NOT NULL e o PostgREST grava null, não o default.
+	if match.Links == nil {
+		match.Links = []string{}
+	}
+	if match.Coupons == nil {
+		match.Coupons = []domain.Coupon{}
+	}
+
+	_, _, err := client.From("matches").Insert(match, false, "", "minimal", "").Execute()
+	if err != nil {
+		return errors.Wrap(err, "[SUPABASE] Failed to save match")
+	}
+
+	return nil
+}
+
+// SessionStorage keeps the Telegram MTProto session in the telegram_sessions
+// table, so containers don't need a persistent volume. Wrap it with
+// telegram.NewEncryptedSessionStorage: the session grants full account access.
+type SessionStorage struct {
+	Client *supabase.Client
+	ID     string
+}
+
+type telegramSession struct {
+	ID   string `json:"id"`
+	Data string `json:"data"`
+}
+
+func (s *SessionStorage) LoadSession(ctx context.Context) ([]byte, error) {
+	var rows []telegramSession
+
+	_, err := s.Client.From("telegram_sessions").Select("*", "", false).Eq("id", s.ID).ExecuteTo(&rows)
+	if err != nil {
+		return nil, errors.Wrap(err, "[SUPABASE] Failed to load telegram session")
+	}
+	if len(rows) == 0 {
+		return nil, session.ErrNotFound
+	}
+
+	data, err := base64.StdEncoding.DecodeString(rows[0].Data)
+	if err != nil {
+		return nil, errors.Wrap(err, "[SUPABASE] Failed to decode telegram session")
+	}
+
+	return data, nil
+}
+
+func (s *SessionStorage) StoreSession(ctx context.Context, data []byte) error {
+	row := telegramSession{ID: s.ID, Data: base64.StdEncoding.EncodeToString(data)}
+
+	_, _, err := s.Client.From("telegram_sessions").Upsert(row, "id", "minimal", "").Execute()
+	if err != nil {
+		return errors.Wrap(err, "[SUPABASE] Failed to store telegram session")
+	}
+
+	return nil
+}
+
+func UpsertProviders(client *supabase.Client, providers []domain.Provider) error {
+	_, _, err := client.From("providers").Upsert(providers, "id", "minimal", "").Execute()
+	if err != nil {
+		return errors.Wrap(err, "[SUPABASE] Failed to upsert providers")
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package telegram
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/go-faster/errors"
+)
+
+type Account struct {
+	Phone string `json:"phone"`
+	// https://core.telegram.org/api/obtaining_api_id
+	AppID      int    `json:"app_id"`
+	AppHash    string `json:"app_hash"`
+	SessionDir string `json:"session_dir"`
+	// Password is the cloud password (2FA); defaults to TELEGRAM_PASSWORD.
+	Password string `json:"password"`
+}
+
+// AccountFromEnv reads TELEGRAM_PHONE, TELEGRAM_APP_ID and TELEGRAM_APP_HASH.
+func AccountFromEnv() (Account, error) {
+	appID, err := strconv.Atoi(os.Getenv("TELEGRAM_APP_ID"))
+	if err != nil {
+		return Account{}, errors.Wrap(err, "[TELEGRAM] invalid TELEGRAM_APP_ID")
+	}
+
+	account := Account{
+		Phone:   os.Getenv("TELEGRAM_PHONE"),
+		AppID:   appID,
+		AppHash: os.Getenv("TELEGRAM_APP_HASH"),
+	}
+	account.setDefaults()
+
+	return account, nil
+}
+
+// AccountsFromEnv reads the accounts from the JSON list in
+// TELEGRAM_ACCOUNTS_FILE, falling back to the single account of AccountFromEnv.
+func AccountsFromEnv() ([]Account, error) {
+	path := os.Getenv("TELEGRAM_ACCOUNTS_FILE")
+	if path == "" {
+		account, err := AccountFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		return []Account{account}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "[TELEGRAM] read TELEGRAM_ACCOUNTS_FILE")
+	}
+
+	var accounts []Account
+	if err := json.Unmarshal(data, &accounts); err != nil {
+		return nil, errors.Wrap(err, "[TELEGRAM] parse TELEGRAM_ACCOUNTS_FILE")
+	}
+	if len(accounts) == 0 {
+		return nil, errors.New("[TELEGRAM] TELEGRAM_ACCOUNTS_FILE has no accounts")
+	}
+
+	for i := range accounts {
+		if accounts[i].Phone == "" || accounts[i].AppID == 0 || accounts[i].AppHash == "" {
+			return nil, errors.Errorf("[TELEGRAM] account %d needs phone, app_id and app_hash", i)
+		}
+		accounts[i].setDefaults()
+	}
+
+	return accounts, nil
+}
+
+func (a *Account) setDefaults() {
+	if a.SessionDir == "" {
+		a.SessionDir = filepath.Join("session", a.Phone)
+	}
+}
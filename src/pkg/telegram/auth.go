@@ -0,0 +1,192 @@
+package telegram
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"bot-telegram/src/pkg/config"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/telegram/auth"
+	"github.com/gotd/td/telegram/auth/qrlogin"
+	"github.com/gotd/td/tg"
+)
+
+// Modos de login.
+const (
+	LoginCode = "code"
+	LoginQR   = "qr"
+)
+
+type AuthConfig struct {
+	// Mode is LoginCode (default) or LoginQR.
+	Mode  string
+	Phone string
+	// Password is the cloud password (2FA), if the account has one.
+	Password string
+	// Code provides the login code sent by Telegram. Defaults to a terminal prompt.
+	Code auth.CodeAuthenticator
+	// CodeFile, when set, is removed before a new code is requested so a
+	// stale code is never reused.
+	CodeFile string
+
+	// LoggedIn and ShowQR are used by the QR login. LoggedIn must be
+	// registered with qrlogin.OnLoginToken before the client starts, and
+	// ShowQR defaults to printing the code to the log.
+	LoggedIn qrlogin.LoggedIn
+	ShowQR   func(ctx context.Context, token qrlogin.Token) error
+}
+
+// AuthConfigFromEnv reads TELEGRAM_LOGIN_MODE, TELEGRAM_PHONE,
+// TELEGRAM_PASSWORD (or TELEGRAM_PASSWORD_FILE) and TELEGRAM_CODE_FILE.
+func AuthConfigFromEnv() (AuthConfig, error) {
+	password, err := config.Secret("TELEGRAM_PASSWORD")
+	if err != nil {
+		return AuthConfig{}, err
+	}
+
+	cfg := AuthConfig{
+		Mode:     os.Getenv("TELEGRAM_LOGIN_MODE"),
+		Phone:    os.Getenv("TELEGRAM_PHONE"),
+		Password: password,
+	}
+	switch cfg.Mode {
+	case "":
+		cfg.Mode = LoginCode
+	case LoginCode, LoginQR:
+	default:
+		return AuthConfig{}, errors.Errorf("[TELEGRAM] unknown TELEGRAM_LOGIN_MODE %q", cfg.Mode)
+	}
+	if path := os.Getenv("TELEGRAM_CODE_FILE"); path != "" {
+		cfg.CodeFile = path
+		cfg.Code = FileCode{Path: path}
+	}
+
+	return cfg, nil
+}
+
+func AuthTelegram(client *telegram.Client, ctx context.Context, cfg AuthConfig) error {
+	if cfg.Mode == LoginQR {
+		return authQR(ctx, client, cfg)
+	}
+	if cfg.Phone == "" {
+		return errors.New("[TELEGRAM] phone is required to log in")
+	}
+
+	code := cfg.Code
+	if code == nil {
+		code = auth.CodeAuthenticatorFunc(promptCode)
+	}
+	if cfg.CodeFile != "" {
+		if err := os.Remove(cfg.CodeFile); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "[TELEGRAM] remove stale code file")
+		}
+	}
+
+	var user auth.UserAuthenticator
+	if cfg.Password != "" {
+		user = auth.Constant(cfg.Phone, cfg.Password, code)
+	} else {
+		user = auth.CodeOnly(cfg.Phone, code)
+	}
+
+	flow := auth.NewFlow(user, auth.SendCodeOptions{})
+
+	// Perform auth if no session is available.
+	if err := client.Auth().IfNecessary(ctx, flow); err != nil {
+		if errors.Is(err, auth.ErrPasswordNotProvided) {
+			return errors.Wrap(err, "[TELEGRAM] account has a cloud password, set TELEGRAM_PASSWORD")
+		}
+		return errors.Wrap(err, "[TELEGRAM] auth")
+	}
+
+	return nil
+}
+
+func promptCode(ctx context.Context, sentCode *tg.AuthSentCode) (string, error) {
+	// NB: Use "golang.org/x/crypto/ssh/terminal" to prompt password.
+	fmt.Print("Enter code: ")
+	code, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(code), nil
+}
+
+// FileCode waits until the login code is written to Path, then removes the file.
+type FileCode struct {
+	Path     string
+	Interval time.Duration
+}
+
+func (f FileCode) Code(ctx context.Context, sentCode *tg.AuthSentCode) (string, error) {
+	interval := f.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	log.Printf("[TELEGRAM] Waiting for login code in %s", f.Path)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		data, err := os.ReadFile(f.Path)
+		switch {
+		case err == nil && strings.TrimSpace(string(data)) != "":
+			if err := os.Remove(f.Path); err != nil {
+				return "", errors.Wrap(err, "remove code file")
+			}
+			return strings.TrimSpace(string(data)), nil
+		case err != nil && !os.IsNotExist(err):
+			return "", errors.Wrap(err, "read code file")
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+// CodeHandler receives the login code over HTTP, in the "code" form field.
+// Codes are only accepted while a login is waiting for one.
+type CodeHandler struct {
+	codes chan string
+}
+
+func NewCodeHandler() *CodeHandler {
+	return &CodeHandler{codes: make(chan string)}
+}
+
+func (h *CodeHandler) Code(ctx context.Context, sentCode *tg.AuthSentCode) (string, error) {
+	log.Printf("[TELEGRAM] Waiting for login code on the HTTP API")
+	select {
+	case code := <-h.codes:
+		return code, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (h *CodeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	code := strings.TrimSpace(r.FormValue("code"))
+	if code == "" {
+		http.Error(w, "missing code", http.StatusBadRequest)
+		return
+	}
+
+	select {
+	case h.codes <- code:
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		http.Error(w, "no login waiting for a code", http.StatusConflict)
+	}
+}
@@ -0,0 +1,120 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"bot-telegram/src/internal/domain"
+
+	"github.com/gotd/td/telegram/query"
+	"github.com/gotd/td/tg"
+)
+
+// ListDialogSources returns every channel, supergroup and legacy group the
+// account has joined.
+func ListDialogSources(ctx context.Context, raw *tg.Client) ([]Source, error) {
+	var sources []Source
+
+	iter := query.GetDialogs(raw).Iter()
+	for iter.Next(ctx) {
+		elem := iter.Value()
+		if elem.Deleted() {
+			continue
+		}
+
+		source, ok := SourceFromPeer(elem.Peer)
+		if !ok {
+			continue
+		}
+
+		switch p := elem.Peer.(type) {
+		case *tg.InputPeerChannel:
+			if channel, ok := elem.Entities.Channels()[p.ChannelID]; ok {
+				source.Title = channel.Title
+				if channel.Megagroup {
+					source.Kind = SourceSupergroup
+				}
+			}
+		case *tg.InputPeerChat:
+			if chat, ok := elem.Entities.Chat(p.ChatID); ok {
+				source.Title = chat.Title
+			}
+		}
+
+		sources = append(sources, source)
+	}
+
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar diálogos: %w", err)
+	}
+
+	return sources, nil
+}
+
+// DescribeProvider fetches the full metadata of a source.
+func DescribeProvider(ctx context.Context, raw *tg.Client, source Source) (domain.Provider, error) {
+	provider := domain.Provider{
+		ProviderID: strconv.FormatInt(source.ID, 10),
+		Kind:       string(source.Kind),
+		Title:      source.Title,
+		UpdatedAt:  time.Now().UTC().Format(time.RFC3339),
+	}
+
+	var (
+		full   *tg.MessagesChatFull
+		err    error
+		chatID int64
+	)
+	switch p := source.Peer.(type) {
+	case *tg.InputPeerChannel:
+		chatID = p.ChannelID
+		full, err = raw.ChannelsGetFullChannel(ctx, &tg.InputChannel{ChannelID: p.ChannelID, AccessHash: p.AccessHash})
+	case *tg.InputPeerChat:
+		chatID = p.ChatID
+		full, err = raw.MessagesGetFullChat(ctx, p.ChatID)
+	default:
+		return provider, fmt.Errorf("tipo de peer não suportado: %T", source.Peer)
+	}
+	if err != nil {
+		return provider, fmt.Errorf("erro ao buscar detalhes de %d: %w", source.ID, err)
+	}
+
+	// Chats também traz o grupo de discussão vinculado e o grupo migrado.
+	for _, chat := range full.Chats {
+		if chat.GetID() != chatID {
+			continue
+		}
+		switch c := chat.(type) {
+		case *tg.Channel:
+			provider.Title = c.Title
+			provider.Username = c.Username
+			provider.Broadcast = c.Broadcast
+			provider.Megagroup = c.Megagroup
+			if c.Megagroup {
+				provider.Kind = string(SourceSupergroup)
+			} else {
+				provider.Kind = string(SourceBroadcast)
+			}
+			if count, ok := c.GetParticipantsCount(); ok {
+				provider.ParticipantsCount = count
+			}
+		case *tg.Chat:
+			provider.Title = c.Title
+			provider.ParticipantsCount = c.ParticipantsCount
+		}
+	}
+
+	switch f := full.FullChat.(type) {
+	case *tg.ChannelFull:
+		provider.Description = f.About
+		if count, ok := f.GetParticipantsCount(); ok {
+			provider.ParticipantsCount = count
+		}
+	case *tg.ChatFull:
+		provider.Description = f.About
+	}
+
+	return provider, nil
+}
@@ -0,0 +1,336 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/constant"
+	"github.com/gotd/td/telegram/query"
+	"github.com/gotd/td/telegram/query/dialogs"
+	"github.com/gotd/td/tg"
+)
+
+// FindFolder returns the folder (dialog filter) whose ID or title is ref.
+// Titles are compared ignoring case.
+func FindFolder(ctx context.Context, raw *tg.Client, ref string) (tg.DialogFilterClass, error) {
+	dialogFilters, err := raw.MessagesGetDialogFilters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar folders: %w", err)
+	}
+
+	id, err := strconv.Atoi(ref)
+	byID := err == nil
+
+	for _, filter := range dialogFilters.GetFilters() {
+		var (
+			filterID int
+			title    string
+		)
+		switch f := filter.(type) {
+		case *tg.DialogFilter:
+			filterID, title = f.ID, f.Title.Text
+		case *tg.DialogFilterChatlist:
+			filterID, title = f.ID, f.Title.Text
+		default:
+			continue
+		}
+
+		if (byID && filterID == id) || strings.EqualFold(strings.TrimSpace(title), strings.TrimSpace(ref)) {
+			return filter, nil
+		}
+	}
+
+	return nil, fmt.Errorf("❌ Pasta %q não encontrada.", ref)
+}
+
+// FolderPeers returns the chats shown in the folder, the way the Telegram
+// apps compute it: pinned and included chats, plus every dialog matching
+// the folder flags (contacts, groups, channels...) that isn't excluded.
+func FolderPeers(ctx context.Context, raw *tg.Client, filter tg.DialogFilterClass) ([]tg.InputPeerClass, error) {
+	var peers []tg.InputPeerClass
+	seen := make(map[int64]bool)
+	add := func(list []tg.InputPeerClass) {
+		for _, peer := range list {
+			if key := peerKey(peer); !seen[key] {
+				seen[key] = true
+				peers = append(peers, peer)
+			}
+		}
+	}
+
+	switch f := filter.(type) {
+	case *tg.DialogFilterChatlist:
+		// Pastas compartilhadas não têm flags nem exclusões.
+		add(f.PinnedPeers)
+		add(f.IncludePeers)
+	case *tg.DialogFilter:
+		add(f.PinnedPeers)
+		add(f.IncludePeers)
+
+		if f.Contacts || f.NonContacts || f.Groups || f.Broadcasts || f.Bots {
+			excluded := make(map[int64]bool, len(f.ExcludePeers))
+			for _, peer := range f.ExcludePeers {
+				excluded[peerKey(peer)] = true
+			}
+
+			matched, err := dialogsMatchingFlags(ctx, raw, f, excluded)
+			if err != nil {
+				return nil, err
+			}
+			add(matched)
+		}
+	default:
+		return nil, fmt.Errorf("tipo de pasta não suportado: %T", filter)
+	}
+
+	return peers, nil
+}
+
+func dialogsMatchingFlags(ctx context.Context, raw *tg.Client, f *tg.DialogFilter, excluded map[int64]bool) ([]tg.InputPeerClass, error) {
+	var peers []tg.InputPeerClass
+
+	now := int(time.Now().Unix())
+	iter := query.GetDialogs(raw).Iter()
+	for iter.Next(ctx) {
+		elem := iter.Value()
+		if elem.Deleted() || excluded[peerKey(elem.Peer)] {
+			continue
+		}
+
+		dialog, ok := elem.Dialog.(*tg.Dialog)
+		if !ok {
+			continue
+		}
+		if f.ExcludeArchived && dialog.FolderID == 1 {
+			continue
+		}
+		if f.ExcludeMuted && dialog.NotifySettings.MuteUntil > now {
+			continue
+		}
+		if f.ExcludeRead && dialog.UnreadCount == 0 && !dialog.UnreadMark {
+			continue
+		}
+
+		if matchesFlags(f, elem) {
+			peers = append(peers, elem.Peer)
+		}
+	}
+
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar diálogos: %w", err)
+	}
+
+	return peers, nil
+}
+
+func matchesFlags(f *tg.DialogFilter, elem dialogs.Elem) bool {
+	switch p := elem.Peer.(type) {
+	case *tg.InputPeerUser:
+		user, ok := elem.Entities.User(p.UserID)
+		switch {
+		case !ok:
+			return false
+		case user.Bot:
+			return f.Bots
+		case user.Contact:
+			return f.Contacts
+		default:
+			return f.NonContacts
+		}
+	case *tg.InputPeerChat:
+		return f.Groups
+	case *tg.InputPeerChannel:
+		channel, ok := elem.Entities.Channels()[p.ChannelID]
+		if !ok {
+			return false
+		}
+		if channel.Broadcast {
+			return f.Broadcasts
+		}
+		return f.Groups
+	}
+
+	return false
+}
+
+// peerKey identifies a peer in the Bot API style, like Source.ID.
+func peerKey(peer tg.InputPeerClass) int64 {
+	var id constant.TDLibPeerID
+
+	switch p := peer.(type) {
+	case *tg.InputPeerUser:
+		id.User(p.UserID)
+	case *tg.InputPeerChat:
+		id.Chat(p.ChatID)
+	case *tg.InputPeerChannel:
+		id.Channel(p.ChannelID)
+	}
+
+	return int64(id)
+}
+
+// ListFolders returns the user folders, normal and shared ones.
+func ListFolders(ctx context.Context, raw *tg.Client) ([]tg.DialogFilterClass, error) {
+	dialogFilters, err := raw.MessagesGetDialogFilters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar folders: %w", err)
+	}
+
+	var folders []tg.DialogFilterClass
+	for _, filter := range dialogFilters.GetFilters() {
+		switch filter.(type) {
+		case *tg.DialogFilter, *tg.DialogFilterChatlist:
+			folders = append(folders, filter)
+		}
+	}
+
+	return folders, nil
+}
+
+// FolderInfo returns the ID and title of a folder.
+func FolderInfo(filter tg.DialogFilterClass) (id int, title string) {
+	switch f := filter.(type) {
+	case *tg.DialogFilter:
+		return f.ID, f.Title.Text
+	case *tg.DialogFilterChatlist:
+		return f.ID, f.Title.Text
+	}
+	return 0, ""
+}
+
+// nextFolderID returns the first free folder ID; 0 and 1 are reserved.
+func nextFolderID(ctx context.Context, raw *tg.Client) (int, error) {
+	folders, err := ListFolders(ctx, raw)
+	if err != nil {
+		return 0, err
+	}
+
+	maxID := 1
+	for _, folder := range folders {
+		if id, _ := FolderInfo(folder); id > maxID {
+			maxID = id
+		}
+	}
+
+	return maxID + 1, nil
+}
+
+// CreateFolder creates a folder with the given type flags and included peers.
+// Telegram rejects folders without flags and without peers.
+func CreateFolder(ctx context.Context, raw *tg.Client, filter *tg.DialogFilter) (int, error) {
+	id, err := nextFolderID(ctx, raw)
+	if err != nil {
+		return 0, err
+	}
+	filter.ID = id
+
+	if err := UpdateFolder(ctx, raw, filter); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func UpdateFolder(ctx context.Context, raw *tg.Client, filter *tg.DialogFilter) error {
+	if filter.IncludePeers == nil {
+		filter.IncludePeers = make([]tg.InputPeerClass, 0)
+	}
+	if filter.ExcludePeers == nil {
+		filter.ExcludePeers = make([]tg.InputPeerClass, 0)
+	}
+	if filter.PinnedPeers == nil {
+		filter.PinnedPeers = make([]tg.InputPeerClass, 0)
+	}
+
+	if _, err := raw.MessagesUpdateDialogFilter(ctx, &tg.MessagesUpdateDialogFilterRequest{
+		ID:     filter.ID,
+		Filter: filter,
+	}); err != nil {
+		return fmt.Errorf("erro ao atualizar pasta: %w", err)
+	}
+
+	return nil
+}
+
+func DeleteFolder(ctx context.Context, raw *tg.Client, folder string) error {
+	filter, err := FindFolder(ctx, raw, folder)
+	if err != nil {
+		return err
+	}
+
+	id, _ := FolderInfo(filter)
+	// Para deletar, chama updateDialogFilter sem o campo filter
+	if _, err := raw.MessagesUpdateDialogFilter(ctx, &tg.MessagesUpdateDialogFilterRequest{ID: id}); err != nil {
+		return fmt.Errorf("erro ao deletar pasta: %w", err)
+	}
+
+	return nil
+}
+
+// AddToFolder includes peer in the folder, referenced by ID or title.
+// Shared folders (chatlists) can't be changed this way.
+func AddToFolder(ctx context.Context, raw *tg.Client, folder string, peer tg.InputPeerClass) error {
+	filter, err := FindFolder(ctx, raw, folder)
+	if err != nil {
+		return err
+	}
+
+	f, ok := filter.(*tg.DialogFilter)
+	if !ok {
+		return fmt.Errorf("a pasta %q é compartilhada e não pode ser alterada", folder)
+	}
+
+	key := peerKey(peer)
+	for _, included := range f.IncludePeers {
+		if peerKey(included) == key {
+			return nil
+		}
+	}
+
+	excluded := f.ExcludePeers[:0]
+	for _, p := range f.ExcludePeers {
+		if peerKey(p) != key {
+			excluded = append(excluded, p)
+		}
+	}
+	f.ExcludePeers = excluded
+	f.IncludePeers = append(f.IncludePeers, peer)
+
+	return UpdateFolder(ctx, raw, f)
+}
+
+// RemoveFromFolder takes peer out of the folder. If the folder type flags
+// would still match it, the peer is excluded explicitly.
+func RemoveFromFolder(ctx context.Context, raw *tg.Client, folder string, peer tg.InputPeerClass) error {
+	filter, err := FindFolder(ctx, raw, folder)
+	if err != nil {
+		return err
+	}
+
+	f, ok := filter.(*tg.DialogFilter)
+	if !ok {
+		return fmt.Errorf("a pasta %q é compartilhada e não pode ser alterada", folder)
+	}
+
+	key := peerKey(peer)
+	without := func(peers []tg.InputPeerClass) []tg.InputPeerClass {
+		kept := make([]tg.InputPeerClass, 0, len(peers))
+		for _, p := range peers {
+			if peerKey(p) != key {
+				kept = append(kept, p)
+			}
+		}
+		return kept
+	}
+	f.IncludePeers = without(f.IncludePeers)
+	f.PinnedPeers = without(f.PinnedPeers)
+
+	if f.Contacts || f.NonContacts || f.Groups || f.Broadcasts || f.Bots {
+		f.ExcludePeers = append(without(f.ExcludePeers), peer)
+	}
+
+	return UpdateFolder(ctx, raw, f)
+}
@@ -0,0 +1,250 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gotd/td/telegram/query"
+	"github.com/gotd/td/tg"
+)
+
+// FolderBackup is a folder layout that can be restored on another account.
+// Peers are stored by ID and username, since access hashes are per account.
+type FolderBackup struct {
+	ID              int       `json:"id"`
+	Title           string    `json:"title"`
+	Emoticon        string    `json:"emoticon,omitempty"`
+	Chatlist        bool      `json:"chatlist,omitempty"`
+	Contacts        bool      `json:"contacts,omitempty"`
+	NonContacts     bool      `json:"non_contacts,omitempty"`
+	Groups          bool      `json:"groups,omitempty"`
+	Broadcasts      bool      `json:"broadcasts,omitempty"`
+	Bots            bool      `json:"bots,omitempty"`
+	ExcludeMuted    bool      `json:"exclude_muted,omitempty"`
+	ExcludeRead     bool      `json:"exclude_read,omitempty"`
+	ExcludeArchived bool      `json:"exclude_archived,omitempty"`
+	Pinned          []PeerRef `json:"pinned,omitempty"`
+	Include         []PeerRef `json:"include,omitempty"`
+	Exclude         []PeerRef `json:"exclude,omitempty"`
+}
+
+type PeerRef struct {
+	// ID is the Bot API style peer ID, like Source.ID.
+	ID       int64  `json:"id"`
+	Username string `json:"username,omitempty"`
+	Title    string `json:"title,omitempty"`
+}
+
+type dialogPeer struct {
+	peer tg.InputPeerClass
+	ref  PeerRef
+}
+
+// dialogIndex maps the Bot API style ID of every dialog to its input peer.
+func dialogIndex(ctx context.Context, raw *tg.Client) (map[int64]dialogPeer, error) {
+	index := make(map[int64]dialogPeer)
+
+	iter := query.GetDialogs(raw).Iter()
+	for iter.Next(ctx) {
+		elem := iter.Value()
+		if elem.Deleted() {
+			continue
+		}
+
+		ref := PeerRef{ID: peerKey(elem.Peer)}
+		switch p := elem.Peer.(type) {
+		case *tg.InputPeerUser:
+			if user, ok := elem.Entities.User(p.UserID); ok {
+				ref.Username = user.Username
+				ref.Title = strings.TrimSpace(user.FirstName + " " + user.LastName)
+			}
+		case *tg.InputPeerChat:
+			if chat, ok := elem.Entities.Chat(p.ChatID); ok {
+				ref.Title = chat.Title
+			}
+		case *tg.InputPeerChannel:
+			if channel, ok := elem.Entities.Channels()[p.ChannelID]; ok {
+				ref.Username = channel.Username
+				ref.Title = channel.Title
+			}
+		}
+
+		index[ref.ID] = dialogPeer{peer: elem.Peer, ref: ref}
+	}
+
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar diálogos: %w", err)
+	}
+
+	return index, nil
+}
+
+// ResolvePeer resolves a Bot API style ID among the dialogs, or a @username
+// or t.me link.
+func ResolvePeer(ctx context.Context, raw *tg.Client, ref string) (tg.InputPeerClass, error) {
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		index, err := dialogIndex(ctx, raw)
+		if err != nil {
+			return nil, err
+		}
+		if dialog, ok := index[id]; ok {
+			return dialog.peer, nil
+		}
+		return nil, fmt.Errorf("chat %d não encontrado nos diálogos", id)
+	}
+
+	username, inviteHash, err := ParseChatLink(ref)
+	if err != nil {
+		return nil, err
+	}
+	if inviteHash != "" {
+		return nil, fmt.Errorf("use o link público ou o ID do chat, não um convite")
+	}
+
+	return resolveUsername(ctx, raw, username)
+}
+
+func resolveUsername(ctx context.Context, raw *tg.Client, username string) (tg.InputPeerClass, error) {
+	resolved, err := raw.ContactsResolveUsername(ctx, &tg.ContactsResolveUsernameRequest{Username: username})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao resolver @%s: %w", username, err)
+	}
+
+	for _, chat := range resolved.Chats {
+		if channel, ok := chat.(*tg.Channel); ok {
+			return &tg.InputPeerChannel{ChannelID: channel.ID, AccessHash: channel.AccessHash}, nil
+		}
+	}
+	for _, u := range resolved.Users {
+		if user, ok := u.(*tg.User); ok {
+			return &tg.InputPeerUser{UserID: user.ID, AccessHash: user.AccessHash}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("@%s não encontrado", username)
+}
+
+// ExportFolders returns the layout of every folder of the account.
+func ExportFolders(ctx context.Context, raw *tg.Client) ([]FolderBackup, error) {
+	folders, err := ListFolders(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := dialogIndex(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := func(peers []tg.InputPeerClass) []PeerRef {
+		var out []PeerRef
+		for _, peer := range peers {
+			key := peerKey(peer)
+			if dialog, ok := index[key]; ok {
+				out = append(out, dialog.ref)
+			} else {
+				out = append(out, PeerRef{ID: key})
+			}
+		}
+		return out
+	}
+
+	backups := make([]FolderBackup, 0, len(folders))
+	for _, folder := range folders {
+		switch f := folder.(type) {
+		case *tg.DialogFilter:
+			backups = append(backups, FolderBackup{
+				ID:              f.ID,
+				Title:           f.Title.Text,
+				Emoticon:        f.Emoticon,
+				Contacts:        f.Contacts,
+				NonContacts:     f.NonContacts,
+				Groups:          f.Groups,
+				Broadcasts:      f.Broadcasts,
+				Bots:            f.Bots,
+				ExcludeMuted:    f.ExcludeMuted,
+				ExcludeRead:     f.ExcludeRead,
+				ExcludeArchived: f.ExcludeArchived,
+				Pinned:          refs(f.PinnedPeers),
+				Include:         refs(f.IncludePeers),
+				Exclude:         refs(f.ExcludePeers),
+			})
+		case *tg.DialogFilterChatlist:
+			backups = append(backups, FolderBackup{
+				ID:       f.ID,
+				Title:    f.Title.Text,
+				Emoticon: f.Emoticon,
+				Chatlist: true,
+				Pinned:   refs(f.PinnedPeers),
+				Include:  refs(f.IncludePeers),
+			})
+		}
+	}
+
+	return backups, nil
+}
+
+// ImportFolders recreates the folders on the account. Folders are matched
+// by title and replaced; shared folders become normal ones. Peers the
+// account can't reach are skipped and returned.
+func ImportFolders(ctx context.Context, raw *tg.Client, backups []FolderBackup) ([]PeerRef, error) {
+	index, err := dialogIndex(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []PeerRef
+	peers := func(refs []PeerRef) []tg.InputPeerClass {
+		out := make([]tg.InputPeerClass, 0, len(refs))
+		for _, ref := range refs {
+			if dialog, ok := index[ref.ID]; ok {
+				out = append(out, dialog.peer)
+				continue
+			}
+			if ref.Username != "" {
+				if peer, err := resolveUsername(ctx, raw, ref.Username); err == nil {
+					out = append(out, peer)
+					continue
+				}
+			}
+			missing = append(missing, ref)
+		}
+		return out
+	}
+
+	for _, backup := range backups {
+		filter := &tg.DialogFilter{
+			Title:           tg.TextWithEntities{Text: backup.Title},
+			Emoticon:        backup.Emoticon,
+			Contacts:        backup.Contacts,
+			NonContacts:     backup.NonContacts,
+			Groups:          backup.Groups,
+			Broadcasts:      backup.Broadcasts,
+			Bots:            backup.Bots,
+			ExcludeMuted:    backup.ExcludeMuted,
+			ExcludeRead:     backup.ExcludeRead,
+			ExcludeArchived: backup.ExcludeArchived,
+			PinnedPeers:     peers(backup.Pinned),
+			IncludePeers:    peers(backup.Include),
+			ExcludePeers:    peers(backup.Exclude),
+		}
+
+		existing, err := FindFolder(ctx, raw, backup.Title)
+		if err == nil {
+			if _, chatlist := existing.(*tg.DialogFilterChatlist); chatlist {
+				return missing, fmt.Errorf("a pasta %q já existe e é compartilhada", backup.Title)
+			}
+			filter.ID, _ = FolderInfo(existing)
+			err = UpdateFolder(ctx, raw, filter)
+		} else {
+			_, err = CreateFolder(ctx, raw, filter)
+		}
+		if err != nil {
+			return missing, fmt.Errorf("erro ao importar pasta %q: %w", backup.Title, err)
+		}
+	}
+
+	return missing, nil
+}
@@ -0,0 +1,156 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/gotd/td/constant"
+	"github.com/gotd/td/tg"
+)
+
+// ParseChatLink splits a @username, t.me/username, t.me/s/username, t.me/+hash
+// or t.me/joinchat/hash reference into a username or an invite hash.
+// t.me/c/<id> links are rejected: they only open for members of the chat.
+func ParseChatLink(link string) (username, inviteHash string, err error) {
+	link = strings.TrimSpace(link)
+	if strings.HasPrefix(link, "@") {
+		return link[1:], "", nil
+	}
+
+	if !strings.Contains(link, "/") {
+		return link, "", nil
+	}
+
+	if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", "", fmt.Errorf("link inválido %q: %w", link, err)
+	}
+	host := strings.TrimPrefix(u.Hostname(), "www.")
+	if host != "t.me" && host != "telegram.me" {
+		return "", "", fmt.Errorf("link inválido %q: não é do Telegram", link)
+	}
+
+	path := strings.Trim(u.Path, "/")
+	first, rest, _ := strings.Cut(path, "/")
+	switch {
+	case strings.HasPrefix(path, "+"):
+		inviteHash = path[1:]
+	case first == "joinchat":
+		inviteHash = rest
+	case first == "c":
+		return "", "", fmt.Errorf("link inválido %q: links t.me/c/ são privados, use o @username ou um convite", link)
+	case first == "s":
+		// Prévia pública do canal.
+		username, _, _ = strings.Cut(rest, "/")
+	default:
+		username = first
+	}
+
+	if username == "" && inviteHash == "" {
+		return "", "", fmt.Errorf("link inválido %q", link)
+	}
+
+	return username, inviteHash, nil
+}
+
+// JoinSource joins the channel or group referenced by link (see ParseChatLink).
+// Joining a chat the account is already in is not an error.
+func JoinSource(ctx context.Context, raw *tg.Client, link string) (Source, error) {
+	username, inviteHash, err := ParseChatLink(link)
+	if err != nil {
+		return Source{}, err
+	}
+
+	if inviteHash != "" {
+		return joinInvite(ctx, raw, inviteHash)
+	}
+
+	resolved, err := raw.ContactsResolveUsername(ctx, &tg.ContactsResolveUsernameRequest{Username: username})
+	if err != nil {
+		return Source{}, fmt.Errorf("erro ao resolver @%s: %w", username, err)
+	}
+
+	peer, ok := resolved.Peer.(*tg.PeerChannel)
+	if !ok {
+		return Source{}, fmt.Errorf("@%s não é um canal ou grupo", username)
+	}
+
+	// Chats também pode trazer outros chats citados, como o grupo de discussão.
+	for _, chat := range resolved.Chats {
+		channel, ok := chat.(*tg.Channel)
+		if !ok || channel.ID != peer.ChannelID {
+			continue
+		}
+
+		if channel.Left {
+			_, err := raw.ChannelsJoinChannel(ctx, &tg.InputChannel{ChannelID: channel.ID, AccessHash: channel.AccessHash})
+			if err != nil {
+				return Source{}, fmt.Errorf("erro ao entrar em @%s: %w", username, err)
+			}
+		}
+
+		source, _ := sourceFromChat(channel)
+		return source, nil
+	}
+
+	return Source{}, fmt.Errorf("@%s: o canal não veio na resposta", username)
+}
+
+func joinInvite(ctx context.Context, raw *tg.Client, hash string) (Source, error) {
+	invite, err := raw.MessagesCheckChatInvite(ctx, hash)
+	if err != nil {
+		return Source{}, fmt.Errorf("erro ao verificar convite: %w", err)
+	}
+
+	if already, ok := invite.(*tg.ChatInviteAlready); ok {
+		if source, ok := sourceFromChat(already.Chat); ok {
+			return source, nil
+		}
+	}
+
+	updates, err := raw.MessagesImportChatInvite(ctx, hash)
+	if err != nil {
+		return Source{}, fmt.Errorf("erro ao aceitar convite: %w", err)
+	}
+
+	withChats, ok := updates.(interface{ GetChats() []tg.ChatClass })
+	if !ok {
+		return Source{}, fmt.Errorf("resposta inesperada ao aceitar convite: %T", updates)
+	}
+	for _, chat := range withChats.GetChats() {
+		if source, ok := sourceFromChat(chat); ok {
+			return source, nil
+		}
+	}
+
+	return Source{}, fmt.Errorf("convite aceito, mas o chat não veio na resposta")
+}
+
+func sourceFromChat(chat tg.ChatClass) (Source, bool) {
+	var id constant.TDLibPeerID
+
+	switch c := chat.(type) {
+	case *tg.Channel:
+		id.Channel(c.ID)
+		kind := SourceBroadcast
+		if c.Megagroup {
+			kind = SourceSupergroup
+		}
+		return Source{
+			ID:    int64(id),
+			Kind:  kind,
+			Title: c.Title,
+			Peer:  &tg.InputPeerChannel{ChannelID: c.ID, AccessHash: c.AccessHash},
+		}, true
+	case *tg.Chat:
+		id.Chat(c.ID)
+		return Source{ID: int64(id), Kind: SourceGroup, Title: c.Title, Peer: &tg.InputPeerChat{ChatID: c.ID}}, true
+	}
+
+	return Source{}, false
+}
@@ -0,0 +1,33 @@
+package telegram
+
+import "testing"
+
+func TestParseChatLink(t *testing.T) {
+	tests := []struct {
+		link, username, invite string
+		wantErr                bool
+	}{
+		{link: "@promocoes", username: "promocoes"},
+		{link: "promocoes", username: "promocoes"},
+		{link: "t.me/promocoes", username: "promocoes"},
+		{link: "https://t.me/promocoes/1234", username: "promocoes"},
+		{link: "https://t.me/s/promocoes", username: "promocoes"},
+		{link: "https://t.me/s/promocoes/1234", username: "promocoes"},
+		{link: "https://t.me/+AbCdEf", invite: "AbCdEf"},
+		{link: "https://telegram.me/joinchat/AbCdEf", invite: "AbCdEf"},
+		{link: "https://t.me/c/1234567890/42", wantErr: true},
+		{link: "https://t.me/s/", wantErr: true},
+		{link: "https://example.com/promocoes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		username, invite, err := ParseChatLink(tt.link)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseChatLink(%q) error = %v, wantErr %v", tt.link, err, tt.wantErr)
+			continue
+		}
+		if username != tt.username || invite != tt.invite {
+			t.Errorf("ParseChatLink(%q) = %q, %q; want %q, %q", tt.link, username, invite, tt.username, tt.invite)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gotd/td/tg"
+)
+
+// Category is a folder the organizer can put sources in. A source belongs to
+// the category whose keywords appear most in its title and recent messages.
+type Category struct {
+	Folder   string   `json:"folder"`
+	Emoji    string   `json:"emoji"`
+	Keywords []string `json:"keywords"`
+}
+
+var DefaultCategories = []Category{
+	{Folder: "Eletrônicos", Emoji: "💻", Keywords: []string{"celular", "smartphone", "iphone", "samsung", "xiaomi", "notebook", "monitor", "fone", "tv", "smart tv", "ssd", "gamer", "console", "playstation", "xbox", "tablet", "kindle", "eletrônicos"}},
+	{Folder: "Moda", Emoji: "👗", Keywords: []string{"tênis", "camiseta", "camisa", "vestido", "calça", "bermuda", "jaqueta", "moda", "nike", "adidas", "roupa", "bolsa", "relógio", "sandália"}},
+	{Folder: "Mercado", Emoji: "🛒", Keywords: []string{"mercado", "supermercado", "café", "arroz", "feijão", "cerveja", "leite", "chocolate", "sabão", "fralda", "shampoo", "papel higiênico", "alimento"}},
+	{Folder: "Cupons", Emoji: "🎟", Keywords: []string{"cupom", "cupons", "código", "off", "desconto", "cashback", "frete grátis", "voucher"}},
+	{Folder: "Casa", Emoji: "🏠", Keywords: []string{"casa", "cozinha", "panela", "air fryer", "aspirador", "geladeira", "colchão", "cama", "ventilador", "ferramenta", "móveis"}},
+}
+
+// OrganizeOptions controls how sources are sampled and classified.
+type OrganizeOptions struct {
+	// Categories defaults to DefaultCategories.
+	Categories []Category
+	// Sample is how many recent messages are read from each source.
+	Sample int
+	// MinScore is the minimum keyword hits to classify a source.
+	MinScore int
+	// Delay between sources, to stay clear of FLOOD_WAIT.
+	Delay time.Duration
+}
+
+func (o *OrganizeOptions) setDefaults() {
+	if len(o.Categories) == 0 {
+		o.Categories = DefaultCategories
+	}
+	if o.Sample <= 0 {
+		o.Sample = 50
+	}
+	if o.MinScore <= 0 {
+		o.MinScore = 3
+	}
+	if o.Delay <= 0 {
+		o.Delay = 300 * time.Millisecond
+	}
+}
+
+// FolderChange is what the organizer wants to do with one folder. ID is zero
+// when the folder does not exist yet.
+type FolderChange struct {
+	ID     int
+	Folder string
+	Emoji  string
+	Add    []Source
+}
+
+// ClassifySource scores the title and the last messages of source against
+// categories and returns the best category, or "" when no category reaches
+// minScore.
+func ClassifySource(ctx context.Context, raw *tg.Client, source Source, categories []Category, sample, minScore int) (string, error) {
+	history, err := raw.MessagesGetHistory(ctx, &tg.MessagesGetHistoryRequest{
+		Peer:  source.Peer,
+		Limit: sample,
+	})
+	if err != nil {
+		return "", fmt.Errorf("erro ao buscar mensagens de %s: %w", source.Title, err)
+	}
+
+	var messages []tg.MessageClass
+	switch h := history.(type) {
+	case *tg.MessagesMessages:
+		messages = h.Messages
+	case *tg.MessagesMessagesSlice:
+		messages = h.Messages
+	case *tg.MessagesChannelMessages:
+		messages = h.Messages
+	}
+
+	texts := make([]string, 0, len(messages))
+	for _, m := range messages {
+		if msg, ok := m.(*tg.Message); ok && msg.Message != "" {
+			texts = append(texts, msg.Message)
+		}
+	}
+
+	return classify(source.Title, texts, categories, minScore), nil
+}
+
+// classify conta as palavras-chave de cada categoria; o título vale 3x.
+func classify(title string, texts []string, categories []Category, minScore int) string {
+	title = " " + normalizeText(title) + " "
+	for i := range texts {
+		texts[i] = " " + normalizeText(texts[i]) + " "
+	}
+
+	best, bestScore := "", 0
+	for _, category := range categories {
+		score := 0
+		for _, keyword := range category.Keywords {
+			keyword = " " + normalizeText(keyword) + " "
+			score += 3 * strings.Count(title, keyword)
+			for _, text := range texts {
+				score += strings.Count(text, keyword)
+			}
+		}
+		if score > bestScore {
+			best, bestScore = category.Folder, score
+		}
+	}
+
+	if bestScore < minScore {
+		return ""
+	}
+	return best
+}
+
+// normalizeText deixa só letras e números em minúsculas, separados por um
+// espaço, para casar palavras inteiras.
+func normalizeText(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), " ")
+}
+
+// PlanFolders classifies sources and returns the changes needed so each
+// classified source is in its category folder. Sources already in the folder
+// are left out; nothing is ever removed.
+func PlanFolders(ctx context.Context, raw *tg.Client, sources []Source, opts OrganizeOptions) ([]FolderChange, error) {
+	opts.setDefaults()
+
+	changes := make(map[string]*FolderChange, len(opts.Categories))
+	for _, category := range opts.Categories {
+		change := &FolderChange{Folder: category.Folder, Emoji: category.Emoji}
+
+		filter, err := FindFolder(ctx, raw, category.Folder)
+		if err == nil {
+			change.ID, _ = FolderInfo(filter)
+			peers, err := FolderPeers(ctx, raw, filter)
+			if err != nil {
+				return nil, err
+			}
+			sources = withoutPeers(sources, peers)
+		}
+		changes[category.Folder] = change
+	}
+
+	for i, source := range sources {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(opts.Delay):
+			}
+		}
+
+		folder, err := ClassifySource(ctx, raw, source, opts.Categories, opts.Sample, opts.MinScore)
+		if err != nil {
+			return nil, err
+		}
+		if folder == "" {
+			continue
+		}
+		changes[folder].Add = append(changes[folder].Add, source)
+	}
+
+	var plan []FolderChange
+	for _, category := range opts.Categories {
+		if change := changes[category.Folder]; len(change.Add) > 0 {
+			plan = append(plan, *change)
+		}
+	}
+
+	return plan, nil
+}
+
+// withoutPeers drops the sources that are already in a category folder, so a
+// source is never moved between folders by a later run.
+func withoutPeers(sources []Source, peers []tg.InputPeerClass) []Source {
+	current := make(map[int64]bool, len(peers))
+	for _, peer := range peers {
+		current[peerKey(peer)] = true
+	}
+
+	kept := sources[:0:0]
+	for _, source := range sources {
+		if !current[source.ID] {
+			kept = append(kept, source)
+		}
+	}
+	return kept
+}
+
+// ApplyFolders creates or updates the folders in plan.
+func ApplyFolders(ctx context.Context, raw *tg.Client, plan []FolderChange) error {
+	for _, change := range plan {
+		if change.ID == 0 {
+			filter := &tg.DialogFilter{
+				Title:    tg.TextWithEntities{Text: change.Folder},
+				Emoticon: change.Emoji,
+			}
+			for _, source := range change.Add {
+				filter.IncludePeers = append(filter.IncludePeers, source.Peer)
+			}
+			if _, err := CreateFolder(ctx, raw, filter); err != nil {
+				return fmt.Errorf("erro ao criar pasta %s: %w", change.Folder, err)
+			}
+			continue
+		}
+
+		filter, err := FindFolder(ctx, raw, fmt.Sprint(change.ID))
+		if err != nil {
+			return err
+		}
+		f, ok := filter.(*tg.DialogFilter)
+		if !ok {
+			return fmt.Errorf("pasta %s é compartilhada e não pode ser alterada", change.Folder)
+		}
+		for _, source := range change.Add {
+			f.IncludePeers = append(f.IncludePeers, source.Peer)
+		}
+		if err := UpdateFolder(ctx, raw, f); err != nil {
+			return fmt.Errorf("erro ao atualizar pasta %s: %w", change.Folder, err)
+		}
+	}
+
+	return nil
+}
+
+// PrintPlan writes plan as a diff, one "+" line per source to be added.
+func PrintPlan(w io.Writer, plan []FolderChange) {
+	if len(plan) == 0 {
+		fmt.Fprintln(w, "Nenhuma alteração")
+		return
+	}
+
+	for _, change := range plan {
+		if change.ID == 0 {
+			fmt.Fprintf(w, "+ 📁 %s %s (nova)\n", change.Folder, change.Emoji)
+		} else {
+			fmt.Fprintf(w, "  📁 %s %s (ID: %d)\n", change.Folder, change.Emoji, change.ID)
+		}
+
+		add := append([]Source(nil), change.Add...)
+		sort.Slice(add, func(i, j int) bool { return add[i].Title < add[j].Title })
+		for _, source := range add {
+			fmt.Fprintf(w, "+     %s (%d)\n", source.Title, source.ID)
+		}
+	}
+}
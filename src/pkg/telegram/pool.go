@@ -0,0 +1,457 @@
+package telegram
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/telegram/auth/qrlogin"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+)
+
+// ErrNoAccount is returned when no account can reach a source.
+var ErrNoAccount = errors.New("[TELEGRAM] no available account for source")
+
+type PoolOptions struct {
+	// Auth is the template used to log in each account; Phone and Password
+	// are taken from the account.
+	Auth AuthConfig
+	// Storage returns where the session of an account is kept; nil uses the
+	// account SessionDir.
+	Storage func(account Account) (telegram.SessionStorage, error)
+}
+
+// Pool runs one client per account and spreads the watched sources among
+// them. Access hashes are per account, so each account only queries the
+// sources it has joined itself.
+type Pool struct {
+	opts     PoolOptions
+	accounts []*poolAccount
+
+	mu sync.Mutex
+	// authMu makes interactive logins sequential, so code prompts don't
+	// interleave.
+	authMu sync.Mutex
+}
+
+type poolAccount struct {
+	Account
+	client     *telegram.Client
+	dispatcher tg.UpdateDispatcher
+	storage    telegram.SessionStorage
+	raw        *tg.Client
+
+	// Protegidos por Pool.mu.
+	ready      bool
+	banned     bool
+	floodUntil time.Time
+	sources    map[int64]Source
+}
+
+func NewPool(accounts []Account, opts PoolOptions) (*Pool, error) {
+	if len(accounts) == 0 {
+		return nil, errors.New("[TELEGRAM] pool needs at least one account")
+	}
+
+	p := &Pool{opts: opts}
+	for _, account := range accounts {
+		var storage telegram.SessionStorage
+		if opts.Storage != nil {
+			var err error
+			if storage, err = opts.Storage(account); err != nil {
+				return nil, errors.Wrapf(err, "[TELEGRAM] session storage for %s", account.Phone)
+			}
+		}
+
+		acc := &poolAccount{Account: account, dispatcher: tg.NewUpdateDispatcher(), storage: storage}
+		client, err := NewClient(account, acc.dispatcher, storage)
+		if err != nil {
+			return nil, errors.Wrapf(err, "[TELEGRAM] client for %s", account.Phone)
+		}
+		acc.client = client
+		p.accounts = append(p.accounts, acc)
+	}
+
+	return p, nil
+}
+
+// Run connects and logs in every account, then calls f as soon as one of
+// them is ready; the others join the pool when they finish logging in, so an
+// account waiting for a login code doesn't hold back the authorized ones. An
+// account whose client stops is restarted with backoff, unless it was banned.
+// Run fails only if every account failed its first login. All clients are
+// closed when f returns.
+func (p *Pool) Run(ctx context.Context, f func(ctx context.Context) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// ready fecha quando uma conta fica pronta ou quando todas já falharam
+	// a primeira tentativa.
+	ready := make(chan struct{})
+	signal := sync.OnceFunc(func() { close(ready) })
+
+	var (
+		wg    sync.WaitGroup
+		tried sync.WaitGroup
+	)
+	for _, acc := range p.accounts {
+		wg.Add(1)
+		tried.Add(1)
+		go func() {
+			defer wg.Done()
+			p.keepRunning(ctx, acc, signal, sync.OnceFunc(tried.Done))
+		}()
+	}
+	go func() {
+		tried.Wait()
+		signal()
+	}()
+
+	select {
+	case <-ready:
+	case <-ctx.Done():
+	}
+	if p.available() == 0 {
+		cancel()
+		wg.Wait()
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return errors.New("[TELEGRAM] no account could log in")
+	}
+
+	err := f(ctx)
+	cancel()
+	wg.Wait()
+
+	return err
+}
+
+// keepRunning runs the client of acc until ctx is done, recreating it with
+// backoff when it stops. onReady is called each time the account is ready and
+// tried after its first attempt, successful or not.
+func (p *Pool) keepRunning(ctx context.Context, acc *poolAccount, onReady, tried func()) {
+	backoff := time.Second
+	for {
+		started := time.Now()
+		err := acc.client.Run(ctx, func(ctx context.Context) error {
+			if err := p.auth(ctx, acc); err != nil {
+				return err
+			}
+
+			p.mu.Lock()
+			acc.raw = acc.client.API()
+			acc.ready = true
+			p.mu.Unlock()
+			onReady()
+			tried()
+
+			<-ctx.Done()
+			return nil
+		})
+		tried()
+
+		p.mu.Lock()
+		acc.ready = false
+		banned := acc.banned
+		p.mu.Unlock()
+		if ctx.Err() != nil {
+			return
+		}
+		if banned {
+			log.Printf("[TELEGRAM] account %s stopped: %v", acc.Phone, err)
+			return
+		}
+
+		if time.Since(started) > time.Minute {
+			backoff = time.Second
+		}
+		log.Printf("[TELEGRAM] account %s stopped: %v; restarting in %s", acc.Phone, err, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, 5*time.Minute)
+
+		// Um client encerrado não roda de novo.
+		client, err := NewClient(acc.Account, acc.dispatcher, acc.storage)
+		if err != nil {
+			log.Printf("[TELEGRAM] account %s: %v", acc.Phone, err)
+			return
+		}
+		acc.client = client
+	}
+}
+
+// auth logs acc in. Only interactive logins are serialized, so code prompts
+// don't interleave; authorized accounts go straight through.
+func (p *Pool) auth(ctx context.Context, acc *poolAccount) error {
+	status, err := acc.client.Auth().Status(ctx)
+	if err != nil {
+		return errors.Wrap(err, "[TELEGRAM] auth status")
+	}
+	if status.Authorized {
+		return nil
+	}
+
+	p.authMu.Lock()
+	defer p.authMu.Unlock()
+
+	cfg := p.opts.Auth
+	cfg.Phone = acc.Phone
+	if acc.Password != "" {
+		cfg.Password = acc.Password
+	}
+	if cfg.Mode == LoginQR {
+		cfg.LoggedIn = qrlogin.OnLoginToken(acc.dispatcher)
+	}
+
+	return AuthTelegram(acc.client, ctx, cfg)
+}
+
+// Size returns how many accounts the pool has.
+func (p *Pool) Size() int {
+	return len(p.accounts)
+}
+
+// ListSourcesFromFolders lists the folder on every available account and
+// returns the union of the sources found.
+func (p *Pool) ListSourcesFromFolders(ctx context.Context, folder string) ([]Source, error) {
+	return p.listSources(ctx, func(ctx context.Context, raw *tg.Client) ([]Source, error) {
+		return ListSourcesFromFolders(ctx, raw, folder)
+	})
+}
+
+// ListDialogSources returns the union of the sources joined by every
+// available account.
+func (p *Pool) ListDialogSources(ctx context.Context) ([]Source, error) {
+	return p.listSources(ctx, ListDialogSources)
+}
+
+// listSources calls list on every available account, records which sources
+// each account can reach and returns the union of them.
+func (p *Pool) listSources(ctx context.Context, list func(ctx context.Context, raw *tg.Client) ([]Source, error)) ([]Source, error) {
+	var (
+		sources []Source
+		seen    = make(map[int64]bool)
+		lastErr error
+	)
+
+	for _, acc := range p.accounts {
+		raw, ok := p.usable(acc)
+		if !ok {
+			continue
+		}
+
+		found, err := list(ctx, raw)
+		if err != nil {
+			lastErr = errors.Wrapf(err, "account %s", acc.Phone)
+			p.handleError(acc, 0, err)
+			continue
+		}
+
+		p.mu.Lock()
+		if acc.sources == nil {
+			acc.sources = make(map[int64]Source, len(found))
+		}
+		for _, source := range found {
+			source.Account = acc.Phone
+			acc.sources[source.ID] = source
+			if !seen[source.ID] {
+				seen[source.ID] = true
+				sources = append(sources, source)
+			}
+		}
+		p.mu.Unlock()
+	}
+
+	if len(sources) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+
+	return sources, nil
+}
+
+// Join joins the channel or group referenced by link with the account that
+// has the fewest sources and adds it to folder. If only adding it to the
+// folder fails, the joined source is returned along with the error.
+func (p *Pool) Join(ctx context.Context, link, folder string) (Source, error) {
+	p.mu.Lock()
+	var acc *poolAccount
+	now := time.Now()
+	for _, candidate := range p.accounts {
+		if !candidate.ready || candidate.banned || now.Before(candidate.floodUntil) {
+			continue
+		}
+		if acc == nil || len(candidate.sources) < len(acc.sources) {
+			acc = candidate
+		}
+	}
+	p.mu.Unlock()
+
+	if acc == nil {
+		return Source{}, errors.New("[TELEGRAM] no available account to join")
+	}
+
+	source, err := JoinSource(ctx, acc.raw, link)
+	if err != nil {
+		p.handleError(acc, 0, err)
+		return Source{}, err
+	}
+
+	source.Account = acc.Phone
+	p.mu.Lock()
+	if acc.sources == nil {
+		acc.sources = make(map[int64]Source)
+	}
+	acc.sources[source.ID] = source
+	p.mu.Unlock()
+
+	if err := AddToFolder(ctx, acc.raw, folder, source.Peer); err != nil {
+		return source, errors.Wrapf(err, "joined %d but could not add it to folder %s", source.ID, folder)
+	}
+
+	return source, nil
+}
+
+// Do calls fn with the account assigned to sourceID and its own copy of the
+// source. Each source is assigned to one of the accounts that joined it;
+// when that account hits FLOOD_WAIT, is banned or lost access to the source,
+// fn is retried with the next one.
+func (p *Pool) Do(ctx context.Context, sourceID int64, fn func(ctx context.Context, raw *tg.Client, source Source) error) error {
+	tried := make(map[*poolAccount]bool)
+	lastErr := ErrNoAccount
+
+	for {
+		acc, raw, source := p.pick(sourceID, tried)
+		if acc == nil {
+			return lastErr
+		}
+		tried[acc] = true
+
+		err := fn(ctx, raw, source)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil || !p.handleError(acc, sourceID, err) {
+			return err
+		}
+		lastErr = err
+	}
+}
+
+// DoAs is Do for a copy of the source listed by account. Legacy group
+// message IDs are only valid for that account, so there fn runs with it or
+// not at all; other sources fail over as in Do.
+func (p *Pool) DoAs(ctx context.Context, sourceID int64, account string, fn func(ctx context.Context, raw *tg.Client, source Source) error) error {
+	if !PerAccountMessageIDs(sourceID) {
+		return p.Do(ctx, sourceID, fn)
+	}
+
+	p.mu.Lock()
+	var (
+		acc    *poolAccount
+		source Source
+	)
+	for _, candidate := range p.accounts {
+		if candidate.Phone == account {
+			acc = candidate
+			source = candidate.sources[sourceID]
+		}
+	}
+	p.mu.Unlock()
+	if acc == nil || source.Peer == nil {
+		return ErrNoAccount
+	}
+
+	raw, ok := p.usable(acc)
+	if !ok {
+		return ErrNoAccount
+	}
+	err := fn(ctx, raw, source)
+	if err != nil && ctx.Err() == nil {
+		p.handleError(acc, sourceID, err)
+	}
+	return err
+}
+
+// pick returns the account assigned to sourceID, skipping the tried and
+// unavailable ones.
+func (p *Pool) pick(sourceID int64, tried map[*poolAccount]bool) (*poolAccount, *tg.Client, Source) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var members []*poolAccount
+	for _, acc := range p.accounts {
+		if _, ok := acc.sources[sourceID]; ok {
+			members = append(members, acc)
+		}
+	}
+	if len(members) == 0 {
+		return nil, nil, Source{}
+	}
+	sort.SliceStable(members, func(i, j int) bool { return members[i].Phone < members[j].Phone })
+
+	now := time.Now()
+	start := int(uint64(sourceID) % uint64(len(members)))
+	for i := range members {
+		acc := members[(start+i)%len(members)]
+		if tried[acc] || !acc.ready || acc.banned || now.Before(acc.floodUntil) {
+			continue
+		}
+		return acc, acc.raw, acc.sources[sourceID]
+	}
+
+	return nil, nil, Source{}
+}
+
+// handleError updates the account state after err and reports whether the
+// call may be retried with another account.
+func (p *Pool) handleError(acc *poolAccount, sourceID int64, err error) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if wait, ok := tgerr.AsFloodWait(err); ok {
+		log.Printf("[TELEGRAM] account %s hit FLOOD_WAIT for %s", acc.Phone, wait)
+		acc.floodUntil = time.Now().Add(wait)
+		return true
+	}
+	if tgerr.Is(err, "USER_DEACTIVATED", "USER_DEACTIVATED_BAN", "AUTH_KEY_UNREGISTERED", "SESSION_REVOKED", "PHONE_NUMBER_BANNED") {
+		log.Printf("[TELEGRAM] account %s is no longer usable: %v", acc.Phone, err)
+		acc.banned = true
+		return true
+	}
+	if sourceID != 0 && tgerr.Is(err, "CHANNEL_PRIVATE", "CHANNEL_INVALID", "CHANNEL_BANNED", "CHAT_ID_INVALID", "PEER_ID_INVALID") {
+		log.Printf("[TELEGRAM] account %s lost access to source %d", acc.Phone, sourceID)
+		delete(acc.sources, sourceID)
+		return true
+	}
+
+	return false
+}
+
+func (p *Pool) usable(acc *poolAccount) (*tg.Client, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return acc.raw, acc.ready && !acc.banned && time.Now().After(acc.floodUntil)
+}
+
+func (p *Pool) available() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n := 0
+	for _, acc := range p.accounts {
+		if acc.ready && !acc.banned {
+			n++
+		}
+	}
+	return n
+}
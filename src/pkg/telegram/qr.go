@@ -0,0 +1,132 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"image/png"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/telegram/auth/qrlogin"
+	"github.com/gotd/td/tgerr"
+	"rsc.io/qr"
+)
+
+func authQR(ctx context.Context, client *telegram.Client, cfg AuthConfig) error {
+	status, err := client.Auth().Status(ctx)
+	if err != nil {
+		return errors.Wrap(err, "[TELEGRAM] auth status")
+	}
+	if status.Authorized {
+		return nil
+	}
+	if cfg.LoggedIn == nil {
+		return errors.New("[TELEGRAM] QR login needs the login token updates, see qrlogin.OnLoginToken")
+	}
+
+	show := cfg.ShowQR
+	if show == nil {
+		show = func(ctx context.Context, token qrlogin.Token) error {
+			return PrintQR(log.Writer(), token)
+		}
+	}
+
+	_, err = client.QR().Auth(ctx, cfg.LoggedIn, show)
+	if tgerr.Is(err, "SESSION_PASSWORD_NEEDED") {
+		if cfg.Password == "" {
+			return errors.Wrap(err, "[TELEGRAM] account has a cloud password, set TELEGRAM_PASSWORD")
+		}
+		_, err = client.Auth().Password(ctx, cfg.Password)
+	}
+	if err != nil {
+		return errors.Wrap(err, "[TELEGRAM] QR auth")
+	}
+
+	return nil
+}
+
+// PrintQR renders the login token as a QR code made of half block characters.
+func PrintQR(w io.Writer, token qrlogin.Token) error {
+	code, err := qr.Encode(token.URL(), qr.L)
+	if err != nil {
+		return errors.Wrap(err, "encode QR")
+	}
+
+	// Margem de 2 módulos ao redor do código, como pede a especificação.
+	const quiet = 2
+	black := func(x, y int) bool {
+		x, y = x-quiet, y-quiet
+		return x >= 0 && y >= 0 && x < code.Size && y < code.Size && code.Black(x, y)
+	}
+
+	var b strings.Builder
+	b.WriteString("Scan the QR code in Telegram > Settings > Devices > Link Desktop Device\n")
+	size := code.Size + 2*quiet
+	for y := 0; y < size; y += 2 {
+		for x := 0; x < size; x++ {
+			top, bottom := black(x, y), black(x, y+1)
+			switch {
+			case top && bottom:
+				b.WriteRune(' ')
+			case top:
+				b.WriteRune('▄')
+			case bottom:
+				b.WriteRune('▀')
+			default:
+				b.WriteRune('█')
+			}
+		}
+		b.WriteByte('\n')
+	}
+	fmt.Fprintf(&b, "Expires at %s\n", token.Expires().Format(time.TimeOnly))
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// QRHandler serves the current login token as a PNG image.
+type QRHandler struct {
+	mu    sync.Mutex
+	token *qrlogin.Token
+}
+
+func NewQRHandler() *QRHandler {
+	return &QRHandler{}
+}
+
+// Show stores the token so it can be served; use it as AuthConfig.ShowQR.
+func (h *QRHandler) Show(ctx context.Context, token qrlogin.Token) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.token = &token
+	return nil
+}
+
+func (h *QRHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	token := h.token
+	h.mu.Unlock()
+
+	if token == nil || time.Now().After(token.Expires()) {
+		http.Error(w, "no QR login in progress", http.StatusNotFound)
+		return
+	}
+
+	img, err := token.Image(qr.M)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := png.Encode(w, img); err != nil {
+		log.Printf("[TELEGRAM] encode QR png: %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+
+	"bot-telegram/src/pkg/config"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/telegram"
+)
+
+// encryptedMagic prefixes every encrypted session, so sessions written
+// before encryption was enabled can still be read.
+var encryptedMagic = []byte("tgsess1:")
+
+// EncryptedSessionStorage encrypts the MTProto session with AES-GCM before
+// handing it to the underlying storage.
+type EncryptedSessionStorage struct {
+	storage telegram.SessionStorage
+	aead    cipher.AEAD
+}
+
+// NewEncryptedSessionStorage wraps storage; key must have 32 bytes (AES-256).
+func NewEncryptedSessionStorage(storage telegram.SessionStorage, key []byte) (*EncryptedSessionStorage, error) {
+	if len(key) != 32 {
+		return nil, errors.Errorf("[TELEGRAM] session key must have 32 bytes, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "[TELEGRAM] session cipher")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "[TELEGRAM] session cipher")
+	}
+
+	return &EncryptedSessionStorage{storage: storage, aead: aead}, nil
+}
+
+// SessionKeyFromEnv reads the base64 encoded TELEGRAM_SESSION_KEY (or
+// TELEGRAM_SESSION_KEY_FILE). It returns nil when no key is configured.
+func SessionKeyFromEnv() ([]byte, error) {
+	encoded, err := config.Secret("TELEGRAM_SESSION_KEY")
+	if err != nil || encoded == "" {
+		return nil, err
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "[TELEGRAM] decode TELEGRAM_SESSION_KEY")
+	}
+
+	return key, nil
+}
+
+func (s *EncryptedSessionStorage) LoadSession(ctx context.Context) ([]byte, error) {
+	data, err := s.storage.LoadSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.HasPrefix(data, encryptedMagic) {
+		// Sessão antiga em texto puro: é cifrada no próximo StoreSession.
+		return data, nil
+	}
+
+	data = data[len(encryptedMagic):]
+	nonceSize := s.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.New("[TELEGRAM] encrypted session is truncated")
+	}
+
+	plain, err := s.aead.Open(nil, data[:nonceSize], data[nonceSize:], encryptedMagic)
+	if err != nil {
+		return nil, errors.Wrap(err, "[TELEGRAM] decrypt session (wrong TELEGRAM_SESSION_KEY?)")
+	}
+
+	return plain, nil
+}
+
+func (s *EncryptedSessionStorage) StoreSession(ctx context.Context, data []byte) error {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return errors.Wrap(err, "[TELEGRAM] session nonce")
+	}
+
+	sealed := append([]byte{}, encryptedMagic...)
+	sealed = append(sealed, nonce...)
+	sealed = s.aead.Seal(sealed, nonce, data, encryptedMagic)
+
+	return s.storage.StoreSession(ctx, sealed)
+}
@@ -0,0 +1,145 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gotd/td/constant"
+	"github.com/gotd/td/tg"
+)
+
+type SourceKind string
+
+const (
+	SourceBroadcast  SourceKind = "channel"
+	SourceSupergroup SourceKind = "supergroup"
+	SourceGroup      SourceKind = "group"
+)
+
+// Source is a chat where promotions are searched: a broadcast channel, a
+// supergroup or a legacy group.
+type Source struct {
+	// ID is the Bot API style peer ID (-100<channel> or -<chat>), so channel
+	// and chat IDs never collide.
+	ID    int64
+	Kind  SourceKind
+	Title string
+	Peer  tg.InputPeerClass
+	// Account is the phone of the pool account this copy of the source
+	// belongs to. Peer is only valid with that account, and so are the
+	// message IDs of legacy groups, see PerAccountMessageIDs.
+	Account string
+}
+
+// PerAccountMessageIDs reports whether the message IDs of the source are
+// numbered per account, as in legacy groups. Channels and supergroups share
+// the same IDs among every member.
+func PerAccountMessageIDs(sourceID int64) bool {
+	return constant.TDLibPeerID(sourceID).IsChat()
+}
+
+// SourceFromPeer returns the source for a channel or legacy group peer.
+// Kind is only a guess until the metadata is fetched, see ListSourcesFromFolders.
+func SourceFromPeer(peer tg.InputPeerClass) (Source, bool) {
+	var id constant.TDLibPeerID
+
+	switch p := peer.(type) {
+	case *tg.InputPeerChannel:
+		id.Channel(p.ChannelID)
+		return Source{ID: int64(id), Kind: SourceBroadcast, Peer: p}, true
+	case *tg.InputPeerChat:
+		id.Chat(p.ChatID)
+		return Source{ID: int64(id), Kind: SourceGroup, Peer: p}, true
+	}
+
+	return Source{}, false
+}
+
+// ListSourcesFromFolders returns every channel, supergroup and legacy group
+// of the folder, referenced by ID or title. Shared folders (chatlists) are
+// supported too.
+func ListSourcesFromFolders(ctx context.Context, raw *tg.Client, folder string) ([]Source, error) {
+	peers, err := folderPeers(ctx, raw, folder)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]Source, 0, len(peers))
+	seen := make(map[int64]bool, len(peers))
+	for _, peer := range peers {
+		source, ok := SourceFromPeer(peer)
+		if !ok || seen[source.ID] {
+			continue
+		}
+		seen[source.ID] = true
+		sources = append(sources, source)
+	}
+
+	if err := describeSources(ctx, raw, sources); err != nil {
+		return nil, err
+	}
+
+	return sources, nil
+}
+
+// describeSources fills the title and kind of the sources.
+func describeSources(ctx context.Context, raw *tg.Client, sources []Source) error {
+	var (
+		channels []tg.InputChannelClass
+		chats    []int64
+	)
+	for _, source := range sources {
+		switch p := source.Peer.(type) {
+		case *tg.InputPeerChannel:
+			channels = append(channels, &tg.InputChannel{ChannelID: p.ChannelID, AccessHash: p.AccessHash})
+		case *tg.InputPeerChat:
+			chats = append(chats, p.ChatID)
+		}
+	}
+
+	var found []tg.ChatClass
+	if len(channels) > 0 {
+		result, err := raw.ChannelsGetChannels(ctx, channels)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar channels: %w", err)
+		}
+		found = append(found, result.GetChats()...)
+	}
+	if len(chats) > 0 {
+		result, err := raw.MessagesGetChats(ctx, chats)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar grupos: %w", err)
+		}
+		found = append(found, result.GetChats()...)
+	}
+
+	byID := make(map[int64]tg.ChatClass, len(found))
+	for _, chat := range found {
+		var id constant.TDLibPeerID
+		switch c := chat.(type) {
+		case *tg.Channel, *tg.ChannelForbidden:
+			id.Channel(c.GetID())
+		default:
+			id.Chat(c.GetID())
+		}
+		byID[int64(id)] = chat
+	}
+
+	for i := range sources {
+		switch c := byID[sources[i].ID].(type) {
+		case *tg.Channel:
+			sources[i].Title = c.Title
+			if c.Megagroup {
+				sources[i].Kind = SourceSupergroup
+			}
+		case *tg.Chat:
+			sources[i].Title = c.Title
+		case *tg.ChannelForbidden:
+			sources[i].Title = c.Title
+		case *tg.ChatForbidden:
+			sources[i].Title = c.Title
+		}
+	}
+
+	return nil
+}
@@ -1,195 +1,222 @@
 package telegram
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strconv"
-	"strings"
 	"time"
 
+	"github.com/go-faster/errors"
 	"github.com/gotd/td/telegram"
-	"github.com/gotd/td/telegram/auth"
 	"github.com/gotd/td/tg"
 )
 
-func ClientTelegram() *telegram.Client {
+// ClientTelegram creates the client for the account configured in the
+// environment; updates are delivered to handler, which may be nil.
+func ClientTelegram(handler telegram.UpdateHandler, storage telegram.SessionStorage) (*telegram.Client, error) {
+	account, err := AccountFromEnv()
+	if err != nil {
+		return nil, err
+	}
 
-	appID := os.Getenv("TELEGRAM_APP_ID")
-	appHash := os.Getenv("TELEGRAM_APP_HASH")
+	return NewClient(account, handler, storage)
+}
 
+// NewClient creates the client for account. When storage is nil the session
+// is kept in account.SessionDir. Either way it is encrypted when
+// TELEGRAM_SESSION_KEY is set.
+func NewClient(account Account, handler telegram.UpdateHandler, storage telegram.SessionStorage) (*telegram.Client, error) {
 	// Setting up session storage.
 	// This is needed to reuse session and not login every time.
-	sessionDir := filepath.Join("session", os.Getenv("TELEGRAM_PHONE"))
-	if err := os.MkdirAll(sessionDir, 0700); err != nil {
-		panic(err)
-	}
+	if storage == nil {
+		if err := os.MkdirAll(account.SessionDir, 0700); err != nil {
+			return nil, errors.Wrap(err, "[TELEGRAM] create session dir")
+		}
 
-	// logFilePath := filepath.Join(sessionDir, "log.jsonl")
-	fmt.Printf("Storing session in %s\n", sessionDir)
+		// logFilePath := filepath.Join(sessionDir, "log.jsonl")
+		fmt.Printf("Storing session in %s\n", account.SessionDir)
 
-	// So, we are storing session information in current directory, under subdirectory "session/phone_hash"
-	sessionStorage := &telegram.FileSessionStorage{
-		Path: filepath.Join(sessionDir, "session.json"),
+		// So, we are storing session information in current directory, under subdirectory "session/phone_hash"
+		storage = &telegram.FileSessionStorage{
+			Path: filepath.Join(account.SessionDir, "session.json"),
+		}
 	}
 
-	options := telegram.Options{
-		// Logger:         lg,              // Passing logger for observability.
-		SessionStorage: sessionStorage, // Setting up session sessionStorage to store auth data.
-		// UpdateHandler:  updatesRecovery, // Setting up handler for updates from server.
+	key, err := SessionKeyFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if key != nil {
+		storage, err = NewEncryptedSessionStorage(storage, key)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	// https://core.telegram.org/api/obtaining_api_id
-	appIDInt, err := strconv.Atoi(appID)
-	if err != nil {
-		panic(err)
+	options := telegram.Options{
+		// Logger:         lg,              // Passing logger for observability.
+		SessionStorage: storage, // Setting up session sessionStorage to store auth data.
+		UpdateHandler:  handler, // Setting up handler for updates from server.
 	}
 
-	client := telegram.NewClient(appIDInt, appHash, options)
+	client := telegram.NewClient(account.AppID, account.AppHash, options)
 
-	return client
+	return client, nil
 }
 
-func AuthTelegram(client *telegram.Client, ctx context.Context) {
+// FetchMessages retorna as mensagens do canal ou grupo com ID maior que minID.
+// Sem cursor (minID 0), retorna só a última página publicada a partir de
+// minDate. Com cursor, minDate é ignorada e as páginas vão das mais antigas
+// para as mais novas, no máximo maxFetchPages: as que passarem disso vêm na
+// próxima busca, a partir da maior ID retornada.
+func FetchMessages(ctx context.Context, raw *tg.Client, targetPeer tg.InputPeerClass, minDate time.Time, minID int) ([]*tg.Message, error) {
+	const limit = 20 // Limitar resultados
+
+	if minID == 0 {
+		results, err := raw.MessagesSearch(ctx, &tg.MessagesSearchRequest{
+			Peer:    targetPeer,
+			Filter:  &tg.InputMessagesFilterEmpty{}, // Necessário para buscar todos os tipos de mensagem
+			Limit:   limit,
+			MinDate: int(minDate.Unix()),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("erro ao buscar mensagens no canal: %w", err)
+		}
+		found, err := foundMessages(results)
+		if err != nil {
+			return nil, err
+		}
+		return onlyMessages(found), nil
+	}
 
-	phone := os.Getenv("TELEGRAM_PHONE")
-	codePrompt := func(ctx context.Context, sentCode *tg.AuthSentCode) (string, error) {
-		// NB: Use "golang.org/x/crypto/ssh/terminal" to prompt password.
-		fmt.Print("Enter code: ")
-		code, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	var messages []*tg.Message
+	for page := 0; page < maxFetchPages; page++ {
+		// AddOffset negativo vira a página: as limit mensagens a partir de
+		// OffsetID, em vez das anteriores a ele.
+		results, err := raw.MessagesGetHistory(ctx, &tg.MessagesGetHistoryRequest{
+			Peer:      targetPeer,
+			OffsetID:  minID + 1,
+			AddOffset: -limit,
+			Limit:     limit,
+			MinID:     minID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("erro ao buscar mensagens no canal: %w", err)
+		}
+		found, err := foundMessages(results)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
-		return strings.TrimSpace(code), nil
+
+		newest := minID
+		for _, msg := range found {
+			newest = max(newest, msg.GetID())
+		}
+		messages = append(messages, onlyMessages(found)...)
+
+		// Página incompleta: não há mais mensagens depois do cursor.
+		if len(found) < limit || newest == minID {
+			break
+		}
+		minID = newest
 	}
 
-	flow := auth.NewFlow(
-		auth.CodeOnly(phone, auth.CodeAuthenticatorFunc(codePrompt)),
-		// auth.Constant(phone, password, auth.CodeAuthenticatorFunc(codePrompt)),
-		auth.SendCodeOptions{},
-	)
+	return messages, nil
+}
 
-	// Perform auth if no session is available.
-	if err := client.Auth().IfNecessary(ctx, flow); err != nil {
-		panic(err)
+func foundMessages(results tg.MessagesMessagesClass) ([]tg.MessageClass, error) {
+	modified, ok := results.AsModified()
+	if !ok {
+		return nil, fmt.Errorf("❌ Tipo de resultado desconhecido: %T", results)
 	}
+	return modified.GetMessages(), nil
 }
 
-func SearchProductInChannel(ctx context.Context, raw *tg.Client, targetPeer *tg.InputPeerChannel, productName string) error {
-	fmt.Printf("\n=== Searching for product: %s - %d - %d ===\n", productName, targetPeer.ChannelID, targetPeer.AccessHash)
-	// Perform the search
-	results, err := raw.MessagesSearch(ctx, &tg.MessagesSearchRequest{
-		Peer: targetPeer,
-		// Q:       productName,
-		Filter:  &tg.InputMessagesFilterEmpty{},             // Necessário para buscar todos os tipos de mensagem
-		Limit:   20,                                         // Limitar resultados
-		MinDate: int(time.Now().Add(-2 * time.Hour).Unix()), // últimas duas horas
-	})
-	if err != nil {
-		return fmt.Errorf("erro ao buscar produto no canal: %w", err)
+// onlyMessages descarta as mensagens de serviço e as vazias.
+func onlyMessages(found []tg.MessageClass) []*tg.Message {
+	var messages []*tg.Message
+	for _, msg := range found {
+		if message, ok := msg.(*tg.Message); ok {
+			messages = append(messages, message)
+		}
 	}
+	return messages
+}
 
-	// Process the search results
-	switch msgs := results.(type) {
-	case *tg.MessagesMessages:
-		fmt.Printf("✅ Encontrado %d mensagens:\n", len(msgs.Messages))
-		for i, msg := range msgs.Messages {
-			if message, ok := msg.(*tg.Message); ok {
-				if !filterProductsByName(message, productName) {
-					continue
-				}
+// GetMessages retorna as mensagens do chat com os IDs pedidos, por ID. As
+// apagadas voltam como *tg.MessageEmpty.
+func GetMessages(ctx context.Context, raw *tg.Client, targetPeer tg.InputPeerClass, ids []int) (map[int]tg.MessageClass, error) {
+	input := make([]tg.InputMessageClass, len(ids))
+	for i, id := range ids {
+		input[i] = &tg.InputMessageID{ID: id}
+	}
 
-				fmt.Printf("🔍 [%d] %s\n", i+1, message.Message)
-				if message.Date > 0 {
-					fmt.Printf("    📅 Data: %d\n", message.Date)
-				}
-				fmt.Println()
+	var (
+		results tg.MessagesMessagesClass
+		err     error
+	)
+	if channel, ok := targetPeer.(*tg.InputPeerChannel); ok {
+		results, err = raw.ChannelsGetMessages(ctx, &tg.ChannelsGetMessagesRequest{
+			Channel: &tg.InputChannel{ChannelID: channel.ChannelID, AccessHash: channel.AccessHash},
+			ID:      input,
+		})
+	} else {
+		results, err = raw.MessagesGetMessages(ctx, input)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar mensagens por ID: %w", err)
+	}
 
-			}
+	modified, ok := results.AsModified()
+	if !ok {
+		return nil, fmt.Errorf("❌ Tipo de resultado desconhecido: %T", results)
+	}
 
-		}
-	case *tg.MessagesMessagesSlice:
-		fmt.Printf("✅ Encontrado %d de %d mensagens:\n", len(msgs.Messages), msgs.Count)
-		for i, msg := range msgs.Messages {
-			if message, ok := msg.(*tg.Message); ok {
-				if !filterProductsByName(message, productName) {
-					continue
-				}
-				fmt.Printf("🔍 [%d] %s\n", i+1, message.Message)
-				if message.Date > 0 {
-					fmt.Printf("    📅 Data: %d\n", message.Date)
-				}
-				fmt.Println()
-			}
-		}
-	case *tg.MessagesChannelMessages:
-		fmt.Printf("✅ Encontrado %d de %d mensagens no canal:\n", len(msgs.Messages), msgs.Count)
-		for i, msg := range msgs.Messages {
-			if message, ok := msg.(*tg.Message); ok {
-				if !filterProductsByName(message, productName) {
-					continue
-				}
-
-				fmt.Printf("🔍 [%d] %s\n", i+1, message.Message)
-				if message.Date > 0 {
-					fmt.Printf("    📅 Data: %d\n", message.Date)
-				}
-				fmt.Println()
-			}
-		}
-	default:
-		fmt.Printf("❌ Tipo de resultado desconhecido: %T\n", results)
+	messages := make(map[int]tg.MessageClass, len(ids))
+	for _, msg := range modified.GetMessages() {
+		messages[msg.GetID()] = msg
 	}
 
-	return nil
+	return messages, nil
 }
 
-func filterProductsByName(message *tg.Message, regexProduct string) bool {
-	match, _ := regexp.MatchString(regexProduct, message.Message)
-	return match
-}
+// maxFetchPages limita cada busca depois de uma parada longa; o resto vem nas
+// buscas seguintes.
+const maxFetchPages = 10
 
 func ListChannelsFromFolders(ctx context.Context, raw *tg.Client, folderID int) ([]*tg.InputPeerChannel, error) {
-	// Obter filtros de diálogo (pastas)
-	dialogFilters, err := raw.MessagesGetDialogFilters(ctx)
+	peers, err := folderPeers(ctx, raw, strconv.Itoa(folderID))
 	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar folders: %w", err)
+		return nil, err
 	}
 
-	// Encontrar a pasta específica
-	var folder *tg.DialogFilter
-	filters := dialogFilters.GetFilters()
-	for _, filter := range filters {
-		if f, ok := filter.(*tg.DialogFilter); ok && f.ID == folderID {
-			folder = f
-			break
+	var includedChannels []*tg.InputPeerChannel
+	for _, chat := range peers {
+		if peer, ok := chat.(*tg.InputPeerChannel); ok {
+			includedChannels = append(includedChannels, peer)
 		}
 	}
 
-	if folder == nil {
-		return nil, fmt.Errorf("❌ Pasta com ID %d não encontrada.", folderID)
+	return includedChannels, nil
+}
+
+// folderPeers retorna os chats da pasta, normal ou compartilhada (chatlist),
+// referenciada pelo ID ou pelo título.
+func folderPeers(ctx context.Context, raw *tg.Client, folder string) ([]tg.InputPeerClass, error) {
+	filter, err := FindFolder(ctx, raw, folder)
+	if err != nil {
+		return nil, err
 	}
 
-	// Listar chats incluídos na pasta
-	includedChats := folder.GetIncludePeers()
+	includedChats, err := FolderPeers(ctx, raw, filter)
+	if err != nil {
+		return nil, err
+	}
 	if len(includedChats) == 0 {
 		return nil, fmt.Errorf("❌ Pasta vazia.\n")
 	}
 
-	var includedChannels []*tg.InputPeerChannel
-	// var includedGroups []*tg.InputPeerChat
-
-	for _, chat := range includedChats {
-		switch peer := chat.(type) {
-		case *tg.InputPeerChannel:
-			includedChannels = append(includedChannels, peer)
-			// case *tg.InputPeerChat:
-			// 	includedGroups = append(includedGroups, peer)
-		}
-	}
-
-	return includedChannels, nil
+	return includedChats, nil
 }